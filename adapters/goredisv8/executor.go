@@ -0,0 +1,22 @@
+// Package goredisv8 runs ftsearch commands on github.com/go-redis/redis/v8
+// clients, alongside the goredisv9 and rueidisadapter packages for the
+// other drivers.
+package goredisv8
+
+import (
+	"github.com/go-redis/redis/v8"
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+)
+
+// NewExecutor returns an executor running commands on any go-redis v8
+// client - a *redis.Client, a *redis.ClusterClient, a *redis.Ring or a
+// failover client. It supports batching through go-redis pipelines.
+func NewExecutor(c redis.UniversalClient) ftsearch.BatchExecutor {
+	return ftsearch.NewGoRedisExecutor(c)
+}
+
+// NewClient returns a search client running its commands on a go-redis
+// v8 client.
+func NewClient(c redis.UniversalClient) *ftsearch.Client {
+	return ftsearch.NewClientWithExecutor(NewExecutor(c))
+}
@@ -0,0 +1,44 @@
+package goredisv8
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/nic-gibson/go-redis-search/ftsearch/ftsearchtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAcceptsUniversalClients(t *testing.T) {
+	require.NotNil(t, NewClient(redis.NewClient(&redis.Options{})))
+	require.NotNil(t, NewClient(redis.NewClusterClient(&redis.ClusterOptions{})))
+	require.NotNil(t, NewClient(redis.NewFailoverClient(&redis.FailoverOptions{})))
+}
+
+func TestExecutor(t *testing.T) {
+	srv, err := ftsearchtest.NewServer(ftsearchtest.New())
+	require.NoError(t, err)
+	defer srv.Close()
+
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer rdb.Close()
+	client := NewClient(rdb)
+	ctx := context.Background()
+
+	_, err = client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex("books").
+		WithSchema(ftsearch.NewSchema().WithIdentifier("title").AttributeType("TEXT")))
+	require.NoError(t, err)
+	require.NoError(t, rdb.HSet(ctx, "book:1", "title", "Learning Go").Err())
+
+	batch := client.NewBatch()
+	found := batch.Search(ftsearch.NewQuery().WithIndex("books").WithQueryString("go"))
+	missing := batch.Search(ftsearch.NewQuery().WithIndex("missing").WithQueryString("go"))
+	require.Error(t, batch.Exec(ctx))
+
+	results, err := found.Result()
+	require.NoError(t, err)
+	require.Equal(t, []string{"book:1"}, results.Keys)
+	_, err = missing.Result()
+	require.ErrorIs(t, err, ftsearch.ErrUnknownIndex)
+}
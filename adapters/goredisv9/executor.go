@@ -0,0 +1,68 @@
+// Package goredisv9 runs ftsearch commands on github.com/redis/go-redis/v9
+// clients. It is a separate module so that v9 is only built by users
+// who need it.
+package goredisv9
+
+import (
+	"context"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/redis/go-redis/v9"
+)
+
+type executor struct {
+	client redis.UniversalClient
+}
+
+var _ ftsearch.BatchExecutor = (*executor)(nil)
+
+// NewExecutor returns an executor running commands on any go-redis v9
+// client, using either RESP2 or RESP3.
+func NewExecutor(c redis.UniversalClient) ftsearch.BatchExecutor {
+	return &executor{client: c}
+}
+
+// NewClient returns a search client running its commands on a go-redis
+// v9 client.
+func NewClient(c redis.UniversalClient) *ftsearch.Client {
+	return ftsearch.NewClientWithExecutor(NewExecutor(c))
+}
+
+func (e *executor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	cmd := newCmd(ctx, args)
+	if err := e.client.Process(ctx, cmd); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return cmd.Val(), nil
+}
+
+func (e *executor) DoMulti(ctx context.Context, cmds ...[]interface{}) []ftsearch.Reply {
+	pending := make([]*redis.Cmd, len(cmds))
+	e.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for n, args := range cmds {
+			pending[n] = newCmd(ctx, args)
+			pipe.Process(ctx, pending[n])
+		}
+		return nil
+	})
+
+	replies := make([]ftsearch.Reply, len(cmds))
+	for n, cmd := range pending {
+		if err := cmd.Err(); err != nil && err != redis.Nil {
+			replies[n].Err = err
+		} else {
+			replies[n].Val = cmd.Val()
+		}
+	}
+	return replies
+}
+
+// newCmd creates the command, marking its key if it has one so that
+// cluster clients route it to the node owning the key's slot
+func newCmd(ctx context.Context, args []interface{}) *redis.Cmd {
+	cmd := redis.NewCmd(ctx, args...)
+	if pos := ftsearch.FirstKeyPos(args); pos > 0 {
+		cmd.SetFirstKeyPos(int8(pos))
+	}
+	return cmd
+}
@@ -0,0 +1,65 @@
+package goredisv9
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/nic-gibson/go-redis-search/ftsearch/ftsearchtest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAcceptsUniversalClients(t *testing.T) {
+	require.NotNil(t, NewClient(redis.NewClient(&redis.Options{})))
+	require.NotNil(t, NewClient(redis.NewClusterClient(&redis.ClusterOptions{})))
+	require.NotNil(t, NewClient(redis.NewFailoverClient(&redis.FailoverOptions{})))
+}
+
+func TestExecutor(t *testing.T) {
+	for _, protocol := range []int{2, 3} {
+		t.Run(fmt.Sprintf("RESP%d", protocol), func(t *testing.T) {
+			srv, err := ftsearchtest.NewServer(ftsearchtest.New())
+			require.NoError(t, err)
+			defer srv.Close()
+
+			rdb := redis.NewClient(&redis.Options{Addr: srv.Addr(), Protocol: protocol})
+			defer rdb.Close()
+			client := NewClient(rdb)
+			ctx := context.Background()
+
+			_, err = client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex("books").
+				WithSchema(ftsearch.NewSchema().WithIdentifier("title").AttributeType("TEXT")))
+			require.NoError(t, err)
+			require.NoError(t, rdb.HSet(ctx, "book:1", "title", "Learning Go").Err())
+
+			raw, err := rdb.Do(ctx, "FT.SEARCH", "books", "go").Result()
+			require.NoError(t, err)
+			if protocol == 3 {
+				require.IsType(t, map[interface{}]interface{}{}, raw)
+			} else {
+				require.IsType(t, []interface{}{}, raw)
+			}
+
+			qry := ftsearch.NewQuery().WithIndex("books").WithQueryString("go")
+			qry.WithScores = true
+			results, err := client.Search(ctx, qry)
+			require.NoError(t, err)
+			require.Equal(t, []string{"book:1"}, results.Keys)
+			require.Equal(t, "Learning Go", results.Data["book:1"].Value["title"])
+			require.Equal(t, 1.0, results.Data["book:1"].Score)
+
+			batch := client.NewBatch()
+			found := batch.Search(ftsearch.NewQuery().WithIndex("books").WithQueryString("go"))
+			missing := batch.Search(ftsearch.NewQuery().WithIndex("missing").WithQueryString("go"))
+			require.Error(t, batch.Exec(ctx))
+
+			results, err = found.Result()
+			require.NoError(t, err)
+			require.Equal(t, []string{"book:1"}, results.Keys)
+			_, err = missing.Result()
+			require.ErrorIs(t, err, ftsearch.ErrUnknownIndex)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/nic-gibson/go-redis-search/adapters/goredisv9
+
+go 1.18
+
+require (
+	github.com/nic-gibson/go-redis-search v0.0.0-20261018201444-769a4ae69b05
+	github.com/redis/go-redis/v9 v9.7.3
+	github.com/stretchr/testify v1.7.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,102 @@
+// Package rueidisadapter runs ftsearch commands on github.com/redis/rueidis
+// clients. It is a separate module so that rueidis is only built by users
+// who need it.
+package rueidisadapter
+
+import (
+	"context"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/redis/rueidis"
+)
+
+type executor struct {
+	client rueidis.Client
+}
+
+var _ ftsearch.BatchExecutor = (*executor)(nil)
+
+// NewExecutor returns an executor running commands on a rueidis client,
+// with or without AlwaysRESP2 set.
+func NewExecutor(c rueidis.Client) ftsearch.BatchExecutor {
+	return &executor{client: c}
+}
+
+// NewClient returns a search client running its commands on a rueidis client.
+func NewClient(c rueidis.Client) *ftsearch.Client {
+	return ftsearch.NewClientWithExecutor(NewExecutor(c))
+}
+
+func (e *executor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	return reply(e.client.Do(ctx, e.build(args)))
+}
+
+func (e *executor) DoMulti(ctx context.Context, cmds ...[]interface{}) []ftsearch.Reply {
+	built := make(rueidis.Commands, len(cmds))
+	for n, args := range cmds {
+		built[n] = e.build(args)
+	}
+
+	results := e.client.DoMulti(ctx, built...)
+	replies := make([]ftsearch.Reply, len(results))
+	for n, result := range results {
+		replies[n].Val, replies[n].Err = reply(result)
+	}
+	return replies
+}
+
+// build converts the arguments to a rueidis command, passing the key
+// separately if there is one so that cluster clients route it correctly
+func (e *executor) build(args []interface{}) rueidis.Completed {
+	tokens := ftsearch.FormatArgs(args)
+	if pos := ftsearch.FirstKeyPos(args); pos > 0 {
+		return e.client.B().Arbitrary(tokens[:pos]...).
+			Keys(tokens[pos]).
+			Args(tokens[pos+1:]...).
+			Build()
+	}
+	return e.client.B().Arbitrary(tokens...).Build()
+}
+
+// redisError wraps errors sent by the server so that they carry the
+// RedisError marker go-redis errors have, which ftsearch uses to tell them
+// apart from network errors.
+type redisError struct {
+	err *rueidis.RedisError
+}
+
+func (e redisError) Error() string { return e.err.Error() }
+
+// RedisError marks the error as coming from the server
+func (e redisError) RedisError() {}
+
+// Unwrap returns the rueidis error
+func (e redisError) Unwrap() error { return e.err }
+
+func serverError(err error) error {
+	if redisErr, ok := rueidis.IsRedisErr(err); ok {
+		return redisError{redisErr}
+	}
+	return err
+}
+
+// reply converts a rueidis result to the types used by go-redis
+func reply(result rueidis.RedisResult) (interface{}, error) {
+	if err := result.Error(); err != nil {
+		if rueidis.IsRedisNil(err) {
+			return nil, nil
+		}
+		return nil, serverError(err)
+	}
+
+	message, err := result.ToMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := message.ToAny()
+	if rueidis.IsRedisNil(err) {
+		return nil, nil
+	}
+	return val, err
+}
@@ -0,0 +1,120 @@
+package rueidisadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/nic-gibson/go-redis-search/ftsearch/ftsearchtest"
+	"github.com/redis/rueidis"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient connects a rueidis client to a fake server, using RESP2
+// if resp2 is set
+func newTestClient(t *testing.T, resp2 bool) rueidis.Client {
+	srv, err := ftsearchtest.NewServer(ftsearchtest.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { srv.Close() })
+
+	rdb, err := rueidis.NewClient(rueidis.ClientOption{
+		InitAddress:       []string{srv.Addr()},
+		AlwaysRESP2:       resp2,
+		DisableCache:      true,
+		ForceSingleClient: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(rdb.Close)
+	return rdb
+}
+
+func TestExecutor(t *testing.T) {
+	for _, resp2 := range []bool{true, false} {
+		t.Run(fmt.Sprintf("AlwaysRESP2=%t", resp2), func(t *testing.T) {
+			rdb := newTestClient(t, resp2)
+			client := NewClient(rdb)
+			ctx := context.Background()
+
+			_, err := client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex("books").
+				WithSchema(ftsearch.NewSchema().WithIdentifier("title").AttributeType("TEXT")))
+			require.NoError(t, err)
+			require.NoError(t, rdb.Do(ctx, rdb.B().Hset().Key("book:1").FieldValue().FieldValue("title", "Learning Go").Build()).Error())
+
+			raw, err := rdb.Do(ctx, rdb.B().Arbitrary("FT.SEARCH", "books", "go").Build()).ToAny()
+			require.NoError(t, err)
+			if resp2 {
+				require.IsType(t, []interface{}{}, raw)
+			} else {
+				require.IsType(t, map[string]interface{}{}, raw)
+			}
+
+			qry := ftsearch.NewQuery().WithIndex("books").WithQueryString("go")
+			qry.WithScores = true
+			results, err := client.Search(ctx, qry)
+			require.NoError(t, err)
+			require.Equal(t, []string{"book:1"}, results.Keys)
+			require.Equal(t, "Learning Go", results.Data["book:1"].Value["title"])
+			require.Equal(t, 1.0, results.Data["book:1"].Score)
+
+			batch := client.NewBatch()
+			found := batch.Search(ftsearch.NewQuery().WithIndex("books").WithQueryString("go"))
+			missing := batch.Search(ftsearch.NewQuery().WithIndex("missing").WithQueryString("go"))
+			require.Error(t, batch.Exec(ctx))
+
+			results, err = found.Result()
+			require.NoError(t, err)
+			require.Equal(t, []string{"book:1"}, results.Keys)
+			_, err = missing.Result()
+			require.ErrorIs(t, err, ftsearch.ErrUnknownIndex)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	rdb := newTestClient(t, true)
+	e := &executor{client: rdb}
+
+	get := e.build([]interface{}{"FT.SUGGET", "suggestions", "hel", "MAX", int64(3)})
+	require.Equal(t, []string{"FT.SUGGET", "suggestions", "hel", "MAX", "3"}, get.Commands())
+	keyed := rdb.B().Get().Key("suggestions").Build()
+	require.Equal(t, keyed.Slot(), get.Slot())
+
+	search := e.build([]interface{}{"FT.SEARCH", "idx", "hello", "LIMIT", int64(0), 10, "SLOP", int32(2), 1.5, []byte("raw"), true})
+	require.Equal(t,
+		[]string{"FT.SEARCH", "idx", "hello", "LIMIT", "0", "10", "SLOP", "2", "1.5", "raw", "1"},
+		search.Commands())
+	keyless := rdb.B().Arbitrary("PING").Build()
+	require.Equal(t, keyless.Slot(), search.Slot())
+	require.NotEqual(t, keyed.Slot(), search.Slot())
+}
+
+func TestReply(t *testing.T) {
+	rdb := newTestClient(t, false)
+	ctx := context.Background()
+
+	val, err := reply(rdb.Do(ctx, rdb.B().Arbitrary("ECHO", "hello").Build()))
+	require.NoError(t, err)
+	require.Equal(t, "hello", val)
+
+	val, err = reply(rdb.Do(ctx, rdb.B().Arbitrary("FT.SEARCH", "missing", "*").Build()))
+	require.Nil(t, val)
+	var marked interface{ RedisError() }
+	require.ErrorAs(t, err, &marked)
+	var redisErr *rueidis.RedisError
+	require.ErrorAs(t, err, &redisErr)
+	require.Equal(t, "missing: no such index", err.Error())
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = reply(rdb.Do(cancelled, rdb.B().Arbitrary("PING").Build()))
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, errors.As(err, &marked))
+}
+
+func TestServerError(t *testing.T) {
+	err := errors.New("connection reset")
+	require.Equal(t, err, serverError(err))
+	require.Nil(t, serverError(nil))
+}
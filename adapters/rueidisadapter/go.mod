@@ -0,0 +1,15 @@
+module github.com/nic-gibson/go-redis-search/adapters/rueidisadapter
+
+go 1.20
+
+require (
+	github.com/nic-gibson/go-redis-search v0.0.0-20261018201444-769a4ae69b05
+	github.com/redis/rueidis v1.0.19
+	github.com/stretchr/testify v1.7.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
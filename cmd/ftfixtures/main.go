@@ -0,0 +1,52 @@
+// ftfixtures refreshes replay fixture files by sending each recorded
+// command, in order, to a live Redis server and saving the new replies.
+// The server must hold the indexes and documents the fixtures expect.
+//
+//	ftfixtures -addr localhost:6379 testdata/*.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nic-gibson/go-redis-search/adapters/goredisv8"
+	"github.com/nic-gibson/go-redis-search/ftsearch/replay"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:6379", "address of the Redis server")
+	password := flag.String("password", "", "Redis password")
+	db := flag.Int("db", 0, "Redis database")
+	dryRun := flag.Bool("n", false, "report changed fixtures without saving them")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: ftfixtures [flags] fixture.json...")
+		os.Exit(2)
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: *addr, Password: *password, DB: *db})
+	defer client.Close()
+	exec := goredisv8.NewExecutor(client)
+
+	failed := false
+	for _, path := range flag.Args() {
+		changed, err := replay.Refresh(context.Background(), path, exec, !*dryRun)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+		case changed:
+			fmt.Printf("%s: updated\n", path)
+		default:
+			fmt.Printf("%s: unchanged\n", path)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
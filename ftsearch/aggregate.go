@@ -0,0 +1,369 @@
+// aggregate provides an interface to RedisSearch's aggregation functionality.
+package ftsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type aggregate struct {
+	Index       string
+	QueryString string
+	Verbatim    bool
+	Load        countedArgs
+	Timeout     time.Duration
+	Steps       []aggregateStep
+	Params      queryParamList
+	Dialect     int32
+}
+
+// aggregateStep is a single step in the aggregation pipeline. Steps are
+// serialized in the order they were added.
+type aggregateStep interface {
+	serialize() []interface{}
+	validate() error
+}
+
+// aggregateReducer is a single REDUCE clause within a GROUPBY step
+type aggregateReducer struct {
+	Function string
+	Args     countedArgs
+	Alias    string
+}
+
+type aggregateGroupBy struct {
+	Fields   countedArgs
+	Reducers []*aggregateReducer
+}
+
+type aggregateApply struct {
+	Expression string
+	Alias      string
+}
+
+type aggregateSortBy struct {
+	Fields countedArgs // each property followed by ASC or DESC
+	Max    int64
+}
+
+type aggregateFilter struct {
+	Expression string
+}
+
+// aggregateLimit is always serialized, unlike queryLimit which omits the
+// default range.
+type aggregateLimit struct {
+	First int64
+	Num   int64
+}
+
+// AggregateResults holds the rows of an aggregate. Warnings are only sent
+// by servers using RESP3; Partial is set when they show the aggregate
+// timed out and returned the rows found so far, so it is never set for
+// RESP2 replies.
+type AggregateResults struct {
+	Count    int64
+	Rows     []map[string]string
+	Warnings []string
+	Partial  bool
+}
+
+func (c *Client) Aggregate(ctx context.Context, agg *aggregate) (*AggregateResults, error) {
+	serialized, err := c.aggregateArgs(ctx, agg)
+	if err != nil {
+		return nil, err
+	}
+	if reply, err := c.do(ctx, serialized); err != nil {
+		return nil, err
+	} else {
+		return agg.parseReply(reply)
+	}
+}
+
+// aggregateArgs validates the aggregate and serializes it, adding the
+// client's TIMEOUT if the aggregate does not set its own
+func (c *Client) aggregateArgs(ctx context.Context, agg *aggregate) ([]interface{}, error) {
+	if err := agg.validate(); err != nil {
+		return nil, err
+	}
+	serialized := agg.serialize()
+	if agg.Timeout == 0 {
+		serialized = append(serialized, c.timeoutArgs(ctx)...)
+	}
+	return serialized, nil
+}
+
+/******************************************************************************
+* Functions operating on the aggregate struct itself						  *
+******************************************************************************/
+
+// NewAggregate creates a new aggregate with defaults set
+// https://redis.io/commands/ft.aggregate/
+func NewAggregate() *aggregate {
+	return &aggregate{
+		QueryString: "*",
+	}
+}
+
+// NewReducer creates a new reducer applying the function to the arguments given
+func NewReducer(function string, args ...string) *aggregateReducer {
+	return &aggregateReducer{
+		Function: function,
+		Args:     args,
+	}
+}
+
+// As sets the name the reducer output is returned as, returning the reducer
+// for chaining.
+func (r *aggregateReducer) As(alias string) *aggregateReducer {
+	r.Alias = alias
+	return r
+}
+
+// String returns the serialized aggregate as a single string. Any quoting
+// required to use it in redis-cli is not done.
+func (a *aggregate) String() string {
+	return fmt.Sprintf("%v", a.serialize())
+}
+
+// WithQueryString sets the raw query string on an aggregate, returning
+// the updated aggregate for chaining.
+func (a *aggregate) WithQueryString(queryString string) *aggregate {
+	a.QueryString = queryString
+	return a
+}
+
+// WithIndex sets the index to be aggregated, returning the
+// updated aggregate for chaining
+func (a *aggregate) WithIndex(index string) *aggregate {
+	a.Index = index
+	return a
+}
+
+// WithLoad sets the fields to be loaded from the documents, returning the
+// updated aggregate for chaining
+func (a *aggregate) WithLoad(fields []string) *aggregate {
+	a.Load = fields
+	return a
+}
+
+// WithTimeout sets the time the server may spend on the aggregate,
+// overriding the client's default. The updated aggregate is returned.
+func (a *aggregate) WithTimeout(timeout time.Duration) *aggregate {
+	a.Timeout = timeout
+	return a
+}
+
+// AddParam sets a parameter referred to as $name in the query string,
+// returning the updated aggregate. Parameters need dialect 2 or later.
+func (a *aggregate) AddParam(name string, value interface{}) *aggregate {
+	a.Params = a.Params.set(name, value)
+	return a
+}
+
+// WithDialect sets the query dialect, returning the updated aggregate for
+// chaining
+func (a *aggregate) WithDialect(dialect int32) *aggregate {
+	a.Dialect = dialect
+	return a
+}
+
+// AddGroupBy adds a GROUPBY step on the fields given with the reducers given,
+// returning the updated aggregate for chaining
+func (a *aggregate) AddGroupBy(fields []string, reducers ...*aggregateReducer) *aggregate {
+	a.Steps = append(a.Steps, &aggregateGroupBy{Fields: fields, Reducers: reducers})
+	return a
+}
+
+// AddApply adds an APPLY step storing the result of the expression in alias,
+// returning the updated aggregate for chaining
+func (a *aggregate) AddApply(expression string, alias string) *aggregate {
+	a.Steps = append(a.Steps, &aggregateApply{Expression: expression, Alias: alias})
+	return a
+}
+
+// AddSortBy adds a SORTBY step. Each field should be a property name
+// (including the @) optionally followed by ASC or DESC. The updated
+// aggregate is returned.
+func (a *aggregate) AddSortBy(fields []string, max int64) *aggregate {
+	a.Steps = append(a.Steps, &aggregateSortBy{Fields: fields, Max: max})
+	return a
+}
+
+// AddFilter adds a FILTER step using the expression given, returning the
+// updated aggregate for chaining
+func (a *aggregate) AddFilter(expression string) *aggregate {
+	a.Steps = append(a.Steps, &aggregateFilter{Expression: expression})
+	return a
+}
+
+// AddLimit adds a LIMIT step, returning the updated aggregate for chaining
+func (a *aggregate) AddLimit(first int64, num int64) *aggregate {
+	a.Steps = append(a.Steps, &aggregateLimit{First: first, Num: num})
+	return a
+}
+
+// serialize converts an aggregate struct to a slice of interface{}
+// ready for execution against Redis
+func (a *aggregate) serialize() []interface{} {
+	var args = []interface{}{"FT.AGGREGATE", a.Index, a.QueryString}
+
+	if a.Verbatim {
+		args = append(args, "VERBATIM")
+	}
+
+	args = append(args, a.Load.serialize("LOAD")...)
+	args = append(args, serializeTimeout(a.Timeout)...)
+
+	for _, step := range a.Steps {
+		args = append(args, step.serialize()...)
+	}
+
+	args = append(args, a.Params.serialize()...)
+	if a.Dialect != noDialect {
+		args = append(args, "DIALECT", a.Dialect)
+	}
+	return args
+}
+
+// validate checks the steps for errors which would otherwise only be
+// reported by the server
+func (a *aggregate) validate() error {
+	for _, step := range a.Steps {
+		if err := step.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseReply converts an FT.AGGREGATE reply in either the RESP2 or the
+// RESP3 shape into AggregateResults
+func (a *aggregate) parseReply(reply interface{}) (*AggregateResults, error) {
+	if m, ok := replyMap(reply); ok {
+		return a.parseMap(m)
+	}
+	if rawResults, err := replySlice(reply); err != nil {
+		return nil, err
+	} else {
+		return a.parse(rawResults)
+	}
+}
+
+// parse converts the raw RESP2 results of an FT.AGGREGATE into AggregateResults
+func (a *aggregate) parse(rawResults []interface{}) (*AggregateResults, error) {
+	if len(rawResults) == 0 {
+		return nil, newReplyError(elementPath("reply", 0), "an integer", nil)
+	}
+	count, err := decodeInt(elementPath("reply", 0), rawResults[0])
+	if err != nil {
+		return nil, err
+	}
+
+	results := AggregateResults{
+		Count: count,
+		Rows:  make([]map[string]string, 0, len(rawResults)-1),
+	}
+
+	for n, row := range rawResults[1:] {
+		if fields, err := decodeFields(elementPath("reply", n+1), row); err != nil {
+			return nil, err
+		} else {
+			results.Rows = append(results.Rows, fields)
+		}
+	}
+
+	return &results, nil
+}
+
+func (g *aggregateGroupBy) serialize() []interface{} {
+	args := []interface{}{"GROUPBY", len(g.Fields)}
+	for _, field := range g.Fields {
+		args = append(args, field)
+	}
+	for _, reducer := range g.Reducers {
+		args = append(args, reducer.serialize()...)
+	}
+	return args
+}
+
+func (r *aggregateReducer) serialize() []interface{} {
+	args := []interface{}{"REDUCE", r.Function, len(r.Args)}
+	for _, arg := range r.Args {
+		args = append(args, arg)
+	}
+	if r.Alias != "" {
+		args = append(args, "AS", r.Alias)
+	}
+	return args
+}
+
+func (ap *aggregateApply) serialize() []interface{} {
+	return []interface{}{"APPLY", ap.Expression, "AS", ap.Alias}
+}
+
+func (s *aggregateSortBy) serialize() []interface{} {
+	args := s.Fields.serialize("SORTBY")
+	if s.Max > 0 {
+		args = append(args, "MAX", s.Max)
+	}
+	return args
+}
+
+func (f *aggregateFilter) serialize() []interface{} {
+	return []interface{}{"FILTER", f.Expression}
+}
+
+func (l *aggregateLimit) serialize() []interface{} {
+	return []interface{}{"LIMIT", l.First, l.Num}
+}
+
+func (g *aggregateGroupBy) validate() error {
+	for _, field := range g.Fields {
+		if !strings.HasPrefix(field, "@") {
+			return fmt.Errorf("ftsearch: GROUPBY property %q must start with @", field)
+		}
+	}
+	for _, reducer := range g.Reducers {
+		if reducer.Function == "" {
+			return errors.New("ftsearch: REDUCE needs a function")
+		}
+	}
+	return nil
+}
+
+func (ap *aggregateApply) validate() error {
+	if ap.Expression == "" || ap.Alias == "" {
+		return fmt.Errorf("ftsearch: APPLY needs an expression and an alias, got %q and %q", ap.Expression, ap.Alias)
+	}
+	return nil
+}
+
+func (s *aggregateSortBy) validate() error {
+	if len(s.Fields) == 0 {
+		return errors.New("ftsearch: SORTBY needs at least one property")
+	}
+	for _, field := range s.Fields {
+		if !strings.HasPrefix(field, "@") && !strings.EqualFold(field, "ASC") && !strings.EqualFold(field, "DESC") {
+			return fmt.Errorf("ftsearch: SORTBY property %q must start with @", field)
+		}
+	}
+	return nil
+}
+
+func (f *aggregateFilter) validate() error {
+	if f.Expression == "" {
+		return errors.New("ftsearch: FILTER needs an expression")
+	}
+	return nil
+}
+
+func (l *aggregateLimit) validate() error {
+	if l.First < 0 || l.Num < 0 {
+		return fmt.Errorf("ftsearch: LIMIT %d %d must not be negative", l.First, l.Num)
+	}
+	return nil
+}
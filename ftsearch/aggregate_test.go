@@ -0,0 +1,38 @@
+package ftsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateGroupBy(t *testing.T) {
+	const (
+		expected = `[FT.AGGREGATE test @type:{book} LOAD 1 @price GROUPBY 1 @author REDUCE COUNT 0 AS count REDUCE AVG 1 @price AS avg_price SORTBY 2 @count DESC LIMIT 0 5]`
+	)
+	agg := NewAggregate().WithIndex("test").WithQueryString("@type:{book}").
+		WithLoad([]string{"@price"}).
+		AddGroupBy([]string{"@author"},
+			NewReducer("COUNT").As("count"),
+			NewReducer("AVG", "@price").As("avg_price")).
+		AddSortBy([]string{"@count", "DESC"}, 0).
+		AddLimit(0, 5)
+
+	require.Equal(t, expected, agg.String())
+}
+
+func TestAggregateParse(t *testing.T) {
+	raw := []interface{}{
+		int64(2),
+		[]interface{}{"author", "smith", "count", "3"},
+		[]interface{}{"author", "jones", "count", "1"},
+	}
+
+	results, err := NewAggregate().parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), results.Count)
+	require.Equal(t, []map[string]string{
+		{"author": "smith", "count": "3"},
+		{"author": "jones", "count": "1"},
+	}, results.Rows)
+}
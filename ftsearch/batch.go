@@ -0,0 +1,139 @@
+package ftsearch
+
+import (
+	"context"
+	"errors"
+)
+
+// Batch queues searches and aggregates to be sent together in a single
+// round trip, through a pipeline when the executor supports batching.
+// Use it as:
+//
+//	batch := client.NewBatch()
+//	first := batch.Search(qry)
+//	counts := batch.Aggregate(agg)
+//	batch.Exec(ctx)
+//	results, err := first.Result()
+//
+// Each queued command gets its own results and error; one failing does
+// not stop the others.
+type Batch struct {
+	client  *Client
+	pending []batchCommand
+}
+
+// batchCommand is a queued command. args returns its arguments, or an
+// error if it cannot be sent, and set receives its reply, returning the
+// error the command ends with.
+type batchCommand struct {
+	args func(ctx context.Context) ([]interface{}, error)
+	set  func(reply interface{}, err error) error
+}
+
+// BatchSearch is a search queued in a batch. Its results are available
+// once the batch has been run.
+type BatchSearch struct {
+	results *QueryResults
+	err     error
+}
+
+// BatchAggregate is an aggregate queued in a batch. Its results are
+// available once the batch has been run.
+type BatchAggregate struct {
+	results *AggregateResults
+	err     error
+}
+
+// errBatchNotRun is the error of a queued command before Exec
+var errBatchNotRun = errors.New("ftsearch: batch has not been run")
+
+// NewBatch returns an empty batch running on the client
+func (c *Client) NewBatch() *Batch {
+	return &Batch{client: c}
+}
+
+// Len returns the number of commands queued
+func (b *Batch) Len() int {
+	return len(b.pending)
+}
+
+// Search queues a search, returning a handle for its results
+func (b *Batch) Search(qry *query) *BatchSearch {
+	bs := &BatchSearch{err: errBatchNotRun}
+	b.pending = append(b.pending, batchCommand{
+		args: func(ctx context.Context) ([]interface{}, error) {
+			return b.client.searchArgs(ctx, qry)
+		},
+		set: func(reply interface{}, err error) error {
+			if err != nil {
+				bs.results, bs.err = nil, err
+			} else {
+				bs.results, bs.err = qry.parseReply(reply)
+			}
+			return bs.err
+		},
+	})
+	return bs
+}
+
+// Aggregate queues an aggregate, returning a handle for its results
+func (b *Batch) Aggregate(agg *aggregate) *BatchAggregate {
+	ba := &BatchAggregate{err: errBatchNotRun}
+	b.pending = append(b.pending, batchCommand{
+		args: func(ctx context.Context) ([]interface{}, error) {
+			return b.client.aggregateArgs(ctx, agg)
+		},
+		set: func(reply interface{}, err error) error {
+			if err != nil {
+				ba.results, ba.err = nil, err
+			} else {
+				ba.results, ba.err = agg.parseReply(reply)
+			}
+			return ba.err
+		},
+	})
+	return ba
+}
+
+// Exec sends the queued commands and sets the results of each. Commands
+// which fail validation are not sent. The error of the first command to
+// fail is returned, if any. The batch is emptied so it can be reused.
+func (b *Batch) Exec(ctx context.Context) error {
+	pending := b.pending
+	b.pending = nil
+
+	errs := make([]error, len(pending))
+	var cmds [][]interface{}
+	var sent []int
+	for n, cmd := range pending {
+		if args, err := cmd.args(ctx); err != nil {
+			errs[n] = cmd.set(nil, err)
+		} else {
+			cmds = append(cmds, args)
+			sent = append(sent, n)
+		}
+	}
+
+	if len(cmds) > 0 {
+		for n, reply := range b.client.doMulti(ctx, cmds) {
+			errs[sent[n]] = pending[sent[n]].set(reply.Val, reply.Err)
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Result returns the results of the search, or its error
+func (bs *BatchSearch) Result() (*QueryResults, error) {
+	return bs.results, bs.err
+}
+
+// Result returns the results of the aggregate, or its error
+func (ba *BatchAggregate) Result() (*AggregateResults, error) {
+	return ba.results, ba.err
+}
@@ -0,0 +1,77 @@
+package ftsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{
+		"FT.SEARCH":    []interface{}{int64(1), "doc:1", []interface{}{"title", "one"}},
+		"FT.AGGREGATE": []interface{}{int64(1), []interface{}{"colour", "red", "count", "2"}},
+	})
+
+	batch := client.NewBatch()
+	search := batch.Search(NewQuery().WithIndex("idx").WithQueryString("one"))
+	invalid := batch.Search(NewQuery().WithIndex("idx").
+		AddFilter(NewQueryFilter("price").WithMinInclusive(2).WithMaxInclusive(1)))
+	counts := batch.Aggregate(NewAggregate().WithIndex("idx"))
+	require.Equal(t, 3, batch.Len())
+
+	_, err := search.Result()
+	require.EqualError(t, err, "ftsearch: batch has not been run")
+
+	err = batch.Exec(context.Background())
+	require.EqualError(t, err, "ftsearch: FILTER price: min 2 is greater than max 1")
+	require.Equal(t, []string{"[FT.SEARCH idx one]", "[FT.AGGREGATE idx *]"}, fake.sent)
+	require.Equal(t, 0, batch.Len())
+
+	results, err := search.Result()
+	require.NoError(t, err)
+	require.Equal(t, "one", results.Data["doc:1"].Value["title"])
+
+	_, err = invalid.Result()
+	require.Error(t, err)
+
+	rows, err := counts.Result()
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{{"colour": "red", "count": "2"}}, rows.Rows)
+
+	fake.replies["FT.SEARCH"] = redisTestError("Unknown Index name")
+	batch.Search(NewQuery().WithIndex("missing"))
+	counts = batch.Aggregate(NewAggregate().WithIndex("idx"))
+	require.ErrorIs(t, batch.Exec(context.Background()), ErrUnknownIndex)
+	_, err = counts.Result()
+	require.NoError(t, err)
+}
+
+func TestBatchAggregateValidate(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{"FT.AGGREGATE": []interface{}{int64(0)}})
+	client.WithDefaultTimeout(time.Second)
+
+	batch := client.NewBatch()
+	valid := batch.Aggregate(NewAggregate().WithIndex("idx").AddGroupBy([]string{"@colour"}, NewReducer("COUNT").As("count")))
+	invalid := batch.Aggregate(NewAggregate().WithIndex("idx").AddGroupBy([]string{"colour"}))
+	err := batch.Exec(context.Background())
+	require.EqualError(t, err, `ftsearch: GROUPBY property "colour" must start with @`)
+	require.Equal(t, []string{"[FT.AGGREGATE idx * GROUPBY 1 @colour REDUCE COUNT 0 AS count TIMEOUT 1000]"}, fake.sent)
+
+	_, err = valid.Result()
+	require.NoError(t, err)
+	_, err = invalid.Result()
+	require.Error(t, err)
+
+	for _, agg := range []*aggregate{
+		NewAggregate().AddApply("", "total"),
+		NewAggregate().AddSortBy([]string{"count", "DESC"}, 0),
+		NewAggregate().AddFilter(""),
+		NewAggregate().AddLimit(-1, 10),
+	} {
+		_, err := client.Aggregate(context.Background(), agg)
+		require.Error(t, err, agg.String())
+	}
+	require.Len(t, fake.sent, 1)
+}
@@ -0,0 +1,266 @@
+package ftsearch
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SearchCache caches search results in process. Results are keyed by the
+// serialized query, kept for a fixed time and evicted least recently used
+// first once the cache is full. Concurrent identical searches share a
+// single call to Redis. Results for an index are dropped when the client
+// writes to it; call Invalidate after writing documents. Close the cache
+// once it is no longer used so the client stops notifying it.
+//
+// Cached results are shared between callers and must not be modified.
+type SearchCache struct {
+	client     *Client
+	size       int
+	ttl        time.Duration
+	now        func() time.Time
+	unregister func()
+
+	lock        sync.Mutex
+	entries     map[string]*list.Element
+	recent      *list.List // of *cacheEntry, most recently used first
+	calls       map[string]*cacheCall
+	generations map[string]uint64 // per index, advanced on invalidation
+}
+
+// cacheEntry is a cached result
+type cacheEntry struct {
+	key     string
+	index   string
+	results *QueryResults
+	expires time.Time
+}
+
+// cacheCall is a search in progress which identical searches wait on.
+// panicked holds the value of a panic in the search, which is raised
+// again for the caller that started it.
+type cacheCall struct {
+	index    string
+	done     chan struct{}
+	results  *QueryResults
+	err      error
+	panicked interface{}
+}
+
+const defaultCacheSize = 1000
+
+// NewSearchCache returns a cache of up to size results, each kept for ttl,
+// in front of the client. A size of 0 or less uses a default of 1000 and
+// a ttl of 0 or less keeps results until they are evicted or invalidated.
+func NewSearchCache(client *Client, size int, ttl time.Duration) *SearchCache {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	sc := &SearchCache{
+		client:      client,
+		size:        size,
+		ttl:         ttl,
+		now:         time.Now,
+		entries:     make(map[string]*list.Element),
+		recent:      list.New(),
+		calls:       make(map[string]*cacheCall),
+		generations: make(map[string]uint64),
+	}
+	sc.unregister = client.OnIndexWrite(sc.Invalidate)
+	return sc
+}
+
+// errSearchAbandoned is returned to callers waiting on a search which
+// ended without a result, as when it panicked
+var errSearchAbandoned = errors.New("ftsearch: shared search ended without a result")
+
+// Search returns the cached results of the query if there are any and
+// runs it otherwise. Errors are not cached. The search is run in the
+// background on a context which keeps the values of the caller's but is
+// not cancelled with it, bounded by the client's default timeout or,
+// without one, by the caller's deadline, so identical searches waiting on
+// it are not failed by the caller giving up. Every caller, including the
+// one which started the search, returns as soon as its own context is
+// done. A waiting caller which receives a context error from the search
+// runs it again.
+func (sc *SearchCache) Search(ctx context.Context, qry *query) (*QueryResults, error) {
+	if err := qry.validate(); err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%q", FormatArgs(qry.serialize()))
+
+	for retried := false; ; retried = true {
+		sc.lock.Lock()
+		if results, ok := sc.get(key); ok {
+			sc.lock.Unlock()
+			return results, nil
+		}
+		call, waiting := sc.calls[key]
+		if !waiting {
+			call = &cacheCall{index: qry.Index, done: make(chan struct{})}
+			sc.calls[key] = call
+			go sc.run(ctx, key, sc.generations[qry.Index], qry, call)
+		}
+		sc.lock.Unlock()
+
+		select {
+		case <-call.done:
+			if !waiting && call.panicked != nil {
+				panic(call.panicked)
+			}
+			if waiting && !retried && isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return call.results, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// run makes the search shared by identical callers, caching its results
+// and releasing the callers waiting on it however it ends
+func (sc *SearchCache) run(ctx context.Context, key string, generation uint64, qry *query, call *cacheCall) {
+	call.err = errSearchAbandoned
+	defer close(call.done)
+	defer func() {
+		call.panicked = recover()
+
+		sc.lock.Lock()
+		defer sc.lock.Unlock()
+		if sc.calls[key] == call {
+			delete(sc.calls, key)
+		}
+		// results fetched while the index was invalidated may be stale
+		if call.err == nil && sc.generations[qry.Index] == generation {
+			sc.put(key, qry.Index, call.results)
+		}
+	}()
+
+	ctx, cancel := sc.callContext(ctx)
+	defer cancel()
+	call.results, call.err = sc.client.Search(ctx, qry)
+}
+
+// callContext returns the context a shared search runs on
+func (sc *SearchCache) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := detachedContext{parent: ctx}
+	if sc.client.timeout > 0 {
+		return context.WithTimeout(detached, sc.client.timeout)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+// Close stops the client invalidating the cache and drops every cached
+// result. The cache should not be used afterwards.
+func (sc *SearchCache) Close() {
+	sc.unregister()
+	sc.Purge()
+}
+
+// Invalidate drops the results cached for an index. Searches of the index
+// in progress are not cached when they complete.
+func (sc *SearchCache) Invalidate(index string) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	sc.generations[index]++
+	for key, call := range sc.calls {
+		if call.index == index {
+			delete(sc.calls, key)
+		}
+	}
+	for element := sc.recent.Front(); element != nil; {
+		next := element.Next()
+		if entry := element.Value.(*cacheEntry); entry.index == index {
+			sc.remove(element)
+		}
+		element = next
+	}
+}
+
+// Purge drops every cached result
+func (sc *SearchCache) Purge() {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	for index := range sc.generations {
+		sc.generations[index]++
+	}
+	for _, call := range sc.calls {
+		sc.generations[call.index]++
+	}
+	sc.calls = make(map[string]*cacheCall)
+	sc.entries = make(map[string]*list.Element)
+	sc.recent.Init()
+}
+
+// Len returns the number of results cached, including expired results
+// not yet dropped
+func (sc *SearchCache) Len() int {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	return sc.recent.Len()
+}
+
+// get returns unexpired cached results, marking them as recently used.
+// The lock must be held.
+func (sc *SearchCache) get(key string) (*QueryResults, bool) {
+	element, ok := sc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if sc.ttl > 0 && !sc.now().Before(entry.expires) {
+		sc.remove(element)
+		return nil, false
+	}
+	sc.recent.MoveToFront(element)
+	return entry.results, true
+}
+
+// put caches results, evicting the least recently used if the cache is
+// full. The lock must be held.
+func (sc *SearchCache) put(key string, index string, results *QueryResults) {
+	entry := &cacheEntry{key: key, index: index, results: results, expires: sc.now().Add(sc.ttl)}
+	if element, ok := sc.entries[key]; ok {
+		element.Value = entry
+		sc.recent.MoveToFront(element)
+		return
+	}
+
+	sc.entries[key] = sc.recent.PushFront(entry)
+	for sc.recent.Len() > sc.size {
+		sc.remove(sc.recent.Back())
+	}
+}
+
+// remove drops a cached result. The lock must be held.
+func (sc *SearchCache) remove(element *list.Element) {
+	delete(sc.entries, element.Value.(*cacheEntry).key)
+	sc.recent.Remove(element)
+}
+
+// detachedContext keeps the values of its parent but neither its deadline
+// nor its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
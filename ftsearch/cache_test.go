@@ -0,0 +1,261 @@
+package ftsearch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchCache(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{
+		"FT.SEARCH":    []interface{}{int64(1), "doc:1", []interface{}{"title", "one"}},
+		"FT.DROPINDEX": "OK",
+	})
+	cache := NewSearchCache(client, 2, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	search := func(index string, qs string) {
+		results, err := cache.Search(ctx, NewQuery().WithIndex(index).WithQueryString(qs))
+		require.NoError(t, err)
+		require.Equal(t, "one", results.Data["doc:1"].Value["title"])
+	}
+
+	search("idx", "one")
+	search("idx", "one")
+	require.Len(t, fake.sent, 1)
+
+	// the least recently used result is evicted
+	search("idx", "two")
+	search("idx", "one")
+	search("other", "one")
+	require.Len(t, fake.sent, 3)
+	search("idx", "two")
+	require.Len(t, fake.sent, 4)
+
+	// results expire
+	now = now.Add(time.Minute)
+	search("idx", "two")
+	require.Len(t, fake.sent, 5)
+
+	// writes through the client invalidate the index
+	_, err := client.DropIndex(ctx, NewDropIndex().WithIndex("idx"))
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.Len(), "results for other are kept")
+	search("idx", "two")
+	require.Len(t, fake.sent, 7)
+
+	cache.Purge()
+	require.Equal(t, 0, cache.Len())
+}
+
+// blockingExecutor counts searches, holding each until release is closed
+type blockingExecutor struct {
+	lock    sync.Mutex
+	calls   int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingExecutor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	b.lock.Lock()
+	b.calls++
+	b.lock.Unlock()
+	b.started <- struct{}{}
+	<-b.release
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return []interface{}{int64(0)}, nil
+}
+
+func TestSearchCacheSingleFlight(t *testing.T) {
+	exec := &blockingExecutor{started: make(chan struct{}, 10), release: make(chan struct{})}
+	cache := NewSearchCache(NewClientWithExecutor(exec), 10, 0)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([]*QueryResults, 5)
+	for n := range results {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			var err error
+			if results[n], err = cache.Search(ctx, NewQuery().WithIndex("idx").WithQueryString("*")); err != nil {
+				t.Error(err)
+			}
+		}(n)
+	}
+
+	<-exec.started
+	// give the other searches time to find the one in progress
+	time.Sleep(20 * time.Millisecond)
+	close(exec.release)
+	wg.Wait()
+
+	require.Equal(t, 1, exec.calls)
+	for _, r := range results {
+		require.Same(t, results[0], r)
+	}
+}
+
+func TestSearchCacheInvalidateInFlight(t *testing.T) {
+	exec := &blockingExecutor{started: make(chan struct{}, 10), release: make(chan struct{})}
+	cache := NewSearchCache(NewClientWithExecutor(exec), 10, 0)
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := cache.Search(ctx, NewQuery().WithIndex("idx")); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	<-exec.started
+	cache.Invalidate("idx")
+	close(exec.release)
+	<-done
+	require.Equal(t, 0, cache.Len())
+}
+
+func TestSearchCacheClose(t *testing.T) {
+	client, _ := newFakeClient(map[string]interface{}{
+		"FT.SEARCH":    []interface{}{int64(0)},
+		"FT.DROPINDEX": "OK",
+	})
+	ctx := context.Background()
+
+	var written []string
+	unregister := client.OnIndexWrite(func(index string) { written = append(written, index) })
+	cache := NewSearchCache(client, 10, 0)
+	require.Len(t, client.hooks, 2)
+
+	_, err := cache.Search(ctx, NewQuery().WithIndex("idx"))
+	require.NoError(t, err)
+	cache.Close()
+	require.Len(t, client.hooks, 1)
+	require.Equal(t, 0, cache.Len())
+
+	_, err = client.DropIndex(ctx, NewDropIndex().WithIndex("idx"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"idx"}, written)
+
+	unregister()
+	unregister()
+	require.Empty(t, client.hooks)
+	_, err = client.DropIndex(ctx, NewDropIndex().WithIndex("idx"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"idx"}, written)
+}
+
+func TestSearchCacheLeaderCancelled(t *testing.T) {
+	exec := &blockingExecutor{started: make(chan struct{}, 10), release: make(chan struct{})}
+	cache := NewSearchCache(NewClientWithExecutor(exec), 10, 0)
+	leaderCtx, cancel := context.WithCancel(context.Background())
+
+	leader := make(chan error)
+	go func() {
+		_, err := cache.Search(leaderCtx, NewQuery().WithIndex("idx"))
+		leader <- err
+	}()
+	<-exec.started
+
+	waiter := make(chan error)
+	go func() {
+		_, err := cache.Search(context.Background(), NewQuery().WithIndex("idx"))
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-leader:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the leader did not return when its context was cancelled")
+	}
+
+	close(exec.release)
+	require.NoError(t, <-waiter)
+	require.Equal(t, 1, exec.calls)
+	require.Equal(t, 1, cache.Len())
+}
+
+// stepExecutor runs each search with the next of its steps
+type stepExecutor struct {
+	lock  sync.Mutex
+	steps []func(ctx context.Context) (interface{}, error)
+}
+
+func (s *stepExecutor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	s.lock.Lock()
+	step := s.steps[0]
+	s.steps = s.steps[1:]
+	s.lock.Unlock()
+	return step(ctx)
+}
+
+func TestSearchCacheWaiterRetries(t *testing.T) {
+	started := make(chan struct{})
+	exec := &stepExecutor{steps: []func(ctx context.Context) (interface{}, error){
+		func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		func(ctx context.Context) (interface{}, error) {
+			return []interface{}{int64(0)}, nil
+		},
+	}}
+	client := NewClientWithExecutor(exec).WithDefaultTimeout(50 * time.Millisecond)
+	cache := NewSearchCache(client, 10, 0)
+
+	leader := make(chan error)
+	go func() {
+		_, err := cache.Search(context.Background(), NewQuery().WithIndex("idx"))
+		leader <- err
+	}()
+	<-started
+
+	results, err := cache.Search(context.Background(), NewQuery().WithIndex("idx"))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), results.Count)
+	require.ErrorIs(t, <-leader, context.DeadlineExceeded)
+}
+
+func TestSearchCachePanic(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	exec := &stepExecutor{steps: []func(ctx context.Context) (interface{}, error){
+		func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			panic("executor failed")
+		},
+	}}
+	cache := NewSearchCache(NewClientWithExecutor(exec), 10, 0)
+
+	go func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the leader to panic")
+			}
+		}()
+		cache.Search(context.Background(), NewQuery().WithIndex("idx"))
+	}()
+	<-started
+
+	waiter := make(chan error)
+	go func() {
+		_, err := cache.Search(context.Background(), NewQuery().WithIndex("idx"))
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	require.ErrorIs(t, <-waiter, errSearchAbandoned)
+}
@@ -3,9 +3,8 @@ package ftsearch
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
-	"github.com/go-redis/redis/v8"
 )
 
 type (
@@ -19,6 +18,7 @@ type (
 		identifier    string
 		attribute     string
 		attributeType string
+		coordSystem   CoordSystem
 	}
 )
 
@@ -61,6 +61,9 @@ func (s *schema) serialize() []interface{} {
 	if s.attributeType != "" {
 		args = append(args, s.attributeType)
 	}
+	if s.coordSystem != "" {
+		args = append(args, string(s.coordSystem))
+	}
 	return args
 }
 func (s *schema) AsAttribute(attribute string) *schema {
@@ -71,6 +74,20 @@ func (s *schema) AttributeType(attributeType string) *schema {
 	s.attributeType = attributeType
 	return s
 }
+
+// AsGeoShape makes the attribute a GEOSHAPE field, holding WKT points and
+// polygons in the coordinate system given, returning the schema for
+// chaining
+func (s *schema) AsGeoShape(system CoordSystem) *schema {
+	s.coordSystem = system
+	return s.AttributeType("GEOSHAPE")
+}
+
+// AsGeo makes the attribute a GEO field, holding "lon,lat" positions,
+// returning the schema for chaining
+func (s *schema) AsGeo() *schema {
+	return s.AttributeType("GEO")
+}
 func (q *create) WithSchema(s *schema) *create {
 	q.schemas = append(q.schemas, s)
 	return q
@@ -101,17 +118,18 @@ type (
 	}
 )
 
+// ReIndex drops the index, if it exists, and creates it again
 func (c *Client) ReIndex(ctx context.Context, index string, qry *create) (*CreateIndexResults, error) {
 	dropIndex := NewDropIndex().WithIndex(index)
-	c.DropIndex(ctx, dropIndex)
+	if _, err := c.DropIndex(ctx, dropIndex); err != nil && !errors.Is(err, ErrUnknownIndex) {
+		return nil, err
+	}
 
 	serialized := qry.serialize()
-	cmd := redis.NewCmd(ctx, serialized...)
-	if err := c.client.Process(ctx, cmd); err != nil {
-		return nil, err
-	} else if rawResults, err := cmd.Result(); err != nil {
+	if rawResults, err := c.do(ctx, serialized); err != nil {
 		return nil, err
 	} else {
+		c.indexWritten(qry.Index)
 		return &CreateIndexResults{
 			RawResults: rawResults,
 		}, nil
@@ -120,12 +138,10 @@ func (c *Client) ReIndex(ctx context.Context, index string, qry *create) (*Creat
 
 func (c *Client) CreateIndex(ctx context.Context, qry *create) (*CreateIndexResults, error) {
 	serialized := qry.serialize()
-	cmd := redis.NewCmd(ctx, serialized...)
-	if err := c.client.Process(ctx, cmd); err != nil {
-		return nil, err
-	} else if rawResults, err := cmd.Result(); err != nil {
+	if rawResults, err := c.do(ctx, serialized); err != nil {
 		return nil, err
 	} else {
+		c.indexWritten(qry.Index)
 		return &CreateIndexResults{
 			RawResults: rawResults,
 		}, nil
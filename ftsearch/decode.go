@@ -0,0 +1,133 @@
+package ftsearch
+
+import (
+	"fmt"
+	"strconv"
+)
+
+/******************************************************************************
+* Defensive reply decoding                                                    *
+******************************************************************************/
+
+// ReplyError is returned in place of a panic when a reply does not have
+// the shape expected. Path locates the value within the reply, for example
+// "reply[3][1]" or "reply.results[0].id", and Got is its Go type. When the
+// value is an error sent by the server inside the reply, Err holds it.
+type ReplyError struct {
+	Path string
+	Want string
+	Got  string
+	Err  error
+}
+
+func (e *ReplyError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("ftsearch: expected %s at %s, got error: %v", e.Want, e.Path, e.Err)
+	}
+	return fmt.Sprintf("ftsearch: expected %s at %s, got %s", e.Want, e.Path, e.Got)
+}
+
+// Unwrap returns the server error found in the reply, if any
+func (e *ReplyError) Unwrap() error {
+	return e.Err
+}
+
+// newReplyError describes the unexpected value got at path
+func newReplyError(path string, want string, got interface{}) *ReplyError {
+	e := &ReplyError{Path: path, Want: want, Got: "nil"}
+	if got != nil {
+		e.Got = fmt.Sprintf("%T", got)
+	}
+	if err, ok := got.(error); ok {
+		e.Err = err
+	}
+	return e
+}
+
+// elementPath returns the path of an element of the array at path
+func elementPath(path string, n int) string {
+	return fmt.Sprintf("%s[%d]", path, n)
+}
+
+// memberPath returns the path of a member of the map at path
+func memberPath(path string, name string) string {
+	return path + "." + name
+}
+
+func decodeString(path string, val interface{}) (string, error) {
+	if s, ok := val.(string); ok {
+		return s, nil
+	}
+	return "", newReplyError(path, "a string", val)
+}
+
+func decodeInt(path string, val interface{}) (int64, error) {
+	if i, ok := val.(int64); ok {
+		return i, nil
+	}
+	return 0, newReplyError(path, "an integer", val)
+}
+
+func decodeArray(path string, val interface{}) ([]interface{}, error) {
+	if a, ok := val.([]interface{}); ok {
+		return a, nil
+	}
+	return nil, newReplyError(path, "an array", val)
+}
+
+// decodeFloat reads a double, which RESP2 sends as a string
+func decodeFloat(path string, val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, newReplyError(path, "a number", val)
+}
+
+// decodeScalar formats a field value as a string. ok is false for nil
+// values, which are sent for attributes missing from a document.
+func decodeScalar(path string, val interface{}) (string, bool, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, true, nil
+	case int64:
+		return strconv.FormatInt(v, 10), true, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true, nil
+	case bool:
+		return strconv.FormatBool(v), true, nil
+	default:
+		return "", false, newReplyError(path, "a field value", val)
+	}
+}
+
+// decodeFields converts document fields, sent either as a flat array of
+// names and values or as a RESP3 map, into a map of strings. Fields with
+// nil values are left out.
+func decodeFields(path string, val interface{}) (map[string]string, error) {
+	if m, ok := replyMap(val); ok {
+		results := make(map[string]string, len(m))
+		for name, raw := range m {
+			if value, ok, err := decodeScalar(memberPath(path, name), raw); err != nil {
+				return nil, err
+			} else if ok {
+				results[name] = value
+			}
+		}
+		return results, nil
+	}
+
+	if input, err := decodeArray(path, val); err != nil {
+		return nil, err
+	} else {
+		return toMap(path, input)
+	}
+}
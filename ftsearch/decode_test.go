@@ -0,0 +1,147 @@
+package ftsearch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testServerError string
+
+func (e testServerError) Error() string { return string(e) }
+
+func TestReplyErrorPath(t *testing.T) {
+	qry := NewQuery().WithIndex("idx").WithQueryString("*")
+
+	_, err := qry.parseReply([]interface{}{int64(1), "doc:1", []interface{}{"title", []interface{}{"nested"}}})
+	var replyErr *ReplyError
+	require.True(t, errors.As(err, &replyErr))
+	require.Equal(t, "reply[2][1]", replyErr.Path)
+	require.Equal(t, "[]interface {}", replyErr.Got)
+
+	serverErr := testServerError("WRONGTYPE")
+	_, err = qry.parseReply([]interface{}{int64(1), serverErr, []interface{}{}})
+	require.ErrorIs(t, err, serverErr)
+
+	_, err = qry.parseReply(nil)
+	require.EqualError(t, err, "ftsearch: expected an integer at reply[0], got nil")
+
+	_, err = qry.parseReply(map[string]interface{}{
+		"total_results": int64(1),
+		"results":       []interface{}{map[string]interface{}{"id": int64(7)}},
+	})
+	require.EqualError(t, err, "ftsearch: expected a string at reply.results[0].id, got int64")
+}
+
+func TestToMapValues(t *testing.T) {
+	fields, err := toMap("reply", []interface{}{"name", "ada", "age", int64(36), "missing", nil})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"name": "ada", "age": "36"}, fields)
+
+	_, err = toMap("reply", []interface{}{"name"})
+	require.Error(t, err)
+}
+
+// fuzzReply builds a reply from fuzz data, each byte choosing the next
+// value, so that random nested structures reach the decoders
+type fuzzReply struct {
+	data []byte
+}
+
+func (f *fuzzReply) next() byte {
+	if len(f.data) == 0 {
+		return 0
+	}
+	b := f.data[0]
+	f.data = f.data[1:]
+	return b
+}
+
+func (f *fuzzReply) value(depth int) interface{} {
+	choice := f.next() % 10
+	if depth > 3 && choice >= 6 {
+		choice = 1
+	}
+
+	switch choice {
+	case 0:
+		return nil
+	case 1:
+		return strings.Repeat("x", int(f.next()%4))
+	case 2:
+		return int64(f.next()) - 128
+	case 3:
+		return float64(f.next()) / 8
+	case 4:
+		return testServerError("ERR fuzz")
+	case 5:
+		return "1.5"
+	case 6, 7:
+		array := make([]interface{}, f.next()%6)
+		for n := range array {
+			array[n] = f.value(depth + 1)
+		}
+		return array
+	default:
+		m := make(map[interface{}]interface{})
+		for n := f.next() % 4; n > 0; n-- {
+			m[f.value(4)] = f.value(depth + 1) // keys are always scalars
+		}
+		return m
+	}
+}
+
+// reply returns a reply which, depending on the first byte, is random or
+// starts with a RESP2 or RESP3 shaped header so decoding goes deeper
+func (f *fuzzReply) reply() interface{} {
+	switch f.next() % 3 {
+	case 0:
+		array := []interface{}{int64(f.next())}
+		for n := f.next() % 8; n > 0; n-- {
+			array = append(array, f.value(1))
+		}
+		return array
+	case 1:
+		return map[interface{}]interface{}{
+			"total_results": int64(f.next()),
+			"results":       f.value(1),
+			"warning":       f.value(1),
+		}
+	default:
+		return f.value(0)
+	}
+}
+
+func checkDecodeError(t *testing.T, err error) {
+	var replyErr *ReplyError
+	if err != nil && !errors.As(err, &replyErr) {
+		t.Fatalf("expected a *ReplyError, got %T: %v", err, err)
+	}
+}
+
+func FuzzSearchReply(f *testing.F) {
+	f.Add([]byte{0, 3, 2, 1, 3, 6, 4, 1, 2, 1, 2})
+	f.Add([]byte{1, 2, 6, 2, 8, 2, 1, 2, 6, 1})
+	f.Add([]byte{2, 7, 7, 7, 7, 7})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, flags := range [][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+			qry := NewQuery()
+			qry.WithScores, qry.NoContent = flags[0], flags[1]
+			_, err := qry.parseReply((&fuzzReply{data: data}).reply())
+			checkDecodeError(t, err)
+		}
+	})
+}
+
+func FuzzAggregateReply(f *testing.F) {
+	f.Add([]byte{0, 3, 2, 6, 4, 1, 2, 1, 2})
+	f.Add([]byte{1, 2, 6, 2, 8, 2, 1, 2, 6, 1})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, err := NewAggregate().parseReply((&fuzzReply{data: data}).reply())
+		checkDecodeError(t, err)
+	})
+}
@@ -4,8 +4,6 @@ package ftsearch
 import (
 	"context"
 	"fmt"
-
-	"github.com/go-redis/redis/v8"
 )
 
 type (
@@ -56,12 +54,10 @@ type (
 
 func (c *Client) DropIndex(ctx context.Context, qry *dropindex) (*DropIndexResults, error) {
 	serialized := qry.serialize()
-	cmd := redis.NewCmd(ctx, serialized...)
-	if err := c.client.Process(ctx, cmd); err != nil {
-		return nil, err
-	} else if rawResults, err := cmd.Result(); err != nil {
+	if rawResults, err := c.do(ctx, serialized); err != nil {
 		return nil, err
 	} else {
+		c.indexWritten(qry.Index)
 		return &DropIndexResults{
 			RawResults: rawResults,
 		}, nil
@@ -0,0 +1,112 @@
+package ftsearch
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/******************************************************************************
+* Server errors                                                               *
+******************************************************************************/
+
+// Errors returned by the server are classified from their messages and
+// returned as a *ServerError which matches one of these with errors.Is.
+var (
+	ErrUnknownIndex        = errors.New("ftsearch: unknown index")
+	ErrIndexExists         = errors.New("ftsearch: index already exists")
+	ErrSyntax              = errors.New("ftsearch: syntax error")
+	ErrTimeout             = errors.New("ftsearch: timeout")
+	ErrUnknownField        = errors.New("ftsearch: unknown field")
+	ErrMaxPrefixExpansions = errors.New("ftsearch: max prefix expansions reached")
+	ErrNoSuchCursor        = errors.New("ftsearch: no such cursor")
+	ErrModuleNotLoaded     = errors.New("ftsearch: search module not loaded")
+)
+
+// ServerError is a classified error returned by the server. Kind is the
+// matching sentinel error and Err the error returned by the executor.
+// Offset is the position in the query string the server reported, as it
+// does for syntax errors, or -1.
+type ServerError struct {
+	Kind   error
+	Offset int
+	Err    error
+}
+
+func (e *ServerError) Error() string {
+	return e.Err.Error()
+}
+
+// Is matches the sentinel error the server error was classified as
+func (e *ServerError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the error returned by the executor
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// RedisError marks the error as coming from the server, as go-redis does
+func (e *ServerError) RedisError() {}
+
+// errorClass maps a test on a lower cased server message to a sentinel
+type errorClass struct {
+	matches func(message string) bool
+	kind    error
+}
+
+var syntaxOffset = regexp.MustCompile(`offset (\d+)`)
+
+// errorClasses are tried in order; the first match wins
+var errorClasses = []errorClass{
+	{containsAny("unknown index name", "no such index"), ErrUnknownIndex},
+	{containsAny("index already exists"), ErrIndexExists},
+	{containsAny("timeout limit was reached", "query timed out"), ErrTimeout},
+	{containsAny("max prefix expansions"), ErrMaxPrefixExpansions},
+	{containsAny("cursor not found", "no such cursor"), ErrNoSuchCursor},
+	{containsAny("unknown field", "no such attribute", "not loaded nor in schema", "unknown property"), ErrUnknownField},
+	{containsAny("syntax error"), ErrSyntax},
+	{isUnknownFTCommand, ErrModuleNotLoaded},
+}
+
+func containsAny(fragments ...string) func(string) bool {
+	return func(message string) bool {
+		for _, fragment := range fragments {
+			if strings.Contains(message, fragment) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// isUnknownFTCommand matches the error Redis returns for FT.* commands
+// when the search module is not loaded
+func isUnknownFTCommand(message string) bool {
+	return strings.Contains(message, "unknown command") && strings.Contains(message, "'ft.")
+}
+
+// classifyError converts an error sent by the server into a *ServerError
+// if its message is recognized. Other errors, including network and
+// context errors, are returned unchanged.
+func classifyError(err error) error {
+	if _, ok := err.(interface{ RedisError() }); !ok {
+		return err
+	} else if _, classified := err.(*ServerError); classified {
+		return err
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, class := range errorClasses {
+		if class.matches(message) {
+			serverErr := &ServerError{Kind: class.kind, Offset: -1, Err: err}
+			if match := syntaxOffset.FindStringSubmatch(message); match != nil {
+				serverErr.Offset, _ = strconv.Atoi(match[1])
+			}
+			return serverErr
+		}
+	}
+	return err
+}
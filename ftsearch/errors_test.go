@@ -0,0 +1,66 @@
+package ftsearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// redisTestError carries the RedisError marker of errors sent by the server
+type redisTestError string
+
+func (e redisTestError) Error() string { return string(e) }
+
+func (e redisTestError) RedisError() {}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		message string
+		kind    error
+	}{
+		{"Unknown Index name", ErrUnknownIndex},
+		{"idx: no such index", ErrUnknownIndex},
+		{"Index already exists", ErrIndexExists},
+		{"Syntax error at offset 12 near foo", ErrSyntax},
+		{"Timeout limit was reached", ErrTimeout},
+		{"Unknown field at offset 3 near @titel", ErrUnknownField},
+		{"Property `year` not loaded nor in schema", ErrUnknownField},
+		{"Max prefix expansions limit was reached", ErrMaxPrefixExpansions},
+		{"Cursor not found, id: 5", ErrNoSuchCursor},
+		{"ERR unknown command 'FT.SEARCH', with args beginning with: ", ErrModuleNotLoaded},
+	}
+
+	for _, test := range tests {
+		err := classifyError(redisTestError(test.message))
+		require.ErrorIs(t, err, test.kind, test.message)
+		require.EqualError(t, err, test.message)
+	}
+
+	var serverErr *ServerError
+	require.True(t, errors.As(classifyError(redisTestError("Syntax error at offset 12 near foo")), &serverErr))
+	require.Equal(t, 12, serverErr.Offset)
+
+	plain := errors.New("Unknown Index name")
+	require.Equal(t, plain, classifyError(plain))
+	require.Equal(t, error(redisTestError("ERR other")), classifyError(redisTestError("ERR other")))
+}
+
+func TestReIndexIgnoresUnknownIndex(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{
+		"FT.DROPINDEX": redisTestError("Unknown Index name"),
+		"FT.CREATE":    "OK",
+	})
+
+	_, err := client.ReIndex(context.Background(), "idx", NewCreate().WithIndex("idx"))
+	require.NoError(t, err)
+	require.Len(t, fake.sent, 2)
+
+	client, _ = newFakeClient(map[string]interface{}{
+		"FT.DROPINDEX": redisTestError("ERR NOPERM"),
+		"FT.CREATE":    "OK",
+	})
+	_, err = client.ReIndex(context.Background(), "idx", NewCreate().WithIndex("idx"))
+	require.EqualError(t, err, "ERR NOPERM")
+}
@@ -0,0 +1,221 @@
+// executor defines how commands built by the client are sent to Redis.
+package ftsearch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Executor runs a single command, given as its arguments, returning the
+// reply. Replies use the types go-redis returns: string, int64, float64,
+// []interface{} for arrays and nil (with no error) for nil replies.
+// Errors returned by the server inside an array are left in place.
+type Executor interface {
+	Do(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+// BatchExecutor is implemented by executors which can send several
+// commands in a single round trip. A reply is returned for every
+// command, in order, whether or not others failed.
+type BatchExecutor interface {
+	Executor
+	DoMulti(ctx context.Context, cmds ...[]interface{}) []Reply
+}
+
+// Reply is the result of a single command run through DoMulti
+type Reply struct {
+	Val interface{}
+	Err error
+}
+
+// goRedisExecutor is the default executor, running commands on any
+// go-redis v8 client
+type goRedisExecutor struct {
+	client redis.UniversalClient
+}
+
+// NewGoRedisExecutor returns an executor running commands on a go-redis
+// client. It supports batching through go-redis pipelines.
+func NewGoRedisExecutor(c redis.UniversalClient) BatchExecutor {
+	return &goRedisExecutor{client: c}
+}
+
+func (e *goRedisExecutor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	cmd := newGoRedisCmd(ctx, args)
+	if err := e.client.Process(ctx, cmd); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return cmd.Val(), nil
+}
+
+func (e *goRedisExecutor) DoMulti(ctx context.Context, cmds ...[]interface{}) []Reply {
+	pending := make([]*redis.Cmd, len(cmds))
+	e.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for n, args := range cmds {
+			pending[n] = newGoRedisCmd(ctx, args)
+			pipe.Process(ctx, pending[n])
+		}
+		return nil
+	})
+
+	replies := make([]Reply, len(cmds))
+	for n, cmd := range pending {
+		if err := cmd.Err(); err != nil && err != redis.Nil {
+			replies[n].Err = err
+		} else {
+			replies[n].Val = cmd.Val()
+		}
+	}
+	return replies
+}
+
+// newGoRedisCmd creates the command, marking its key if it has one so
+// that cluster clients route it to the node owning the key's slot
+func newGoRedisCmd(ctx context.Context, args []interface{}) *redis.Cmd {
+	cmd := redis.NewCmd(ctx, args...)
+	if pos := FirstKeyPos(args); pos > 0 {
+		cmd.SetFirstKeyPos(int8(pos))
+	}
+	return cmd
+}
+
+// FirstKeyPos returns the position in args of the first key used by the
+// command, or 0 if it has none. Commands addressing an index rather than
+// a key can be served by any node of a cluster so report no key.
+func FirstKeyPos(args []interface{}) int {
+	if len(args) < 2 {
+		return 0
+	}
+	name, _ := args[0].(string)
+	switch strings.ToUpper(name) {
+	case "FT.SUGADD", "FT.SUGGET", "FT.SUGDEL", "FT.SUGLEN":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// FormatArgs converts command arguments to strings the way go-redis
+// writes them, for executors and tools which need them as text
+func FormatArgs(args []interface{}) []string {
+	tokens := make([]string, len(args))
+	for n, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			tokens[n] = v
+		case []byte:
+			tokens[n] = string(v)
+		case int:
+			tokens[n] = strconv.Itoa(v)
+		case int32:
+			tokens[n] = strconv.FormatInt(int64(v), 10)
+		case int64:
+			tokens[n] = strconv.FormatInt(v, 10)
+		case float64:
+			tokens[n] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			if v {
+				tokens[n] = "1"
+			} else {
+				tokens[n] = "0"
+			}
+		case nil:
+			tokens[n] = ""
+		default:
+			tokens[n] = fmt.Sprint(v)
+		}
+	}
+	return tokens
+}
+
+/******************************************************************************
+* Reply handling                                                              *
+******************************************************************************/
+
+// do runs a single command on the client's executor, classifying any
+// error sent by the server
+func (c *Client) do(ctx context.Context, args []interface{}) (interface{}, error) {
+	reply, err := c.exec.Do(ctx, args...)
+	return reply, classifyError(err)
+}
+
+// doMulti runs the commands in a single batch if the executor supports it
+// and one at a time otherwise.
+func (c *Client) doMulti(ctx context.Context, cmds [][]interface{}) []Reply {
+	var replies []Reply
+	if batch, ok := c.exec.(BatchExecutor); ok {
+		replies = batch.DoMulti(ctx, cmds...)
+	} else {
+		replies = make([]Reply, len(cmds))
+		for n, args := range cmds {
+			replies[n].Val, replies[n].Err = c.exec.Do(ctx, args...)
+		}
+	}
+
+	for n := range replies {
+		replies[n].Err = classifyError(replies[n].Err)
+	}
+	return replies
+}
+
+func (c *Client) doSlice(ctx context.Context, args []interface{}) ([]interface{}, error) {
+	if reply, err := c.do(ctx, args); err != nil {
+		return nil, err
+	} else {
+		return replySlice(reply)
+	}
+}
+
+func (c *Client) doInt(ctx context.Context, args []interface{}) (int64, error) {
+	if reply, err := c.do(ctx, args); err != nil {
+		return 0, err
+	} else {
+		return replyInt(reply)
+	}
+}
+
+func (c *Client) doStrings(ctx context.Context, args []interface{}) ([]string, error) {
+	if reply, err := c.do(ctx, args); err != nil {
+		return nil, err
+	} else {
+		return replyStrings(reply)
+	}
+}
+
+func replySlice(reply interface{}) ([]interface{}, error) {
+	switch r := reply.(type) {
+	case []interface{}:
+		return r, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, newReplyError("reply", "an array", reply)
+	}
+}
+
+func replyInt(reply interface{}) (int64, error) {
+	if r, ok := reply.(int64); ok {
+		return r, nil
+	}
+	return 0, newReplyError("reply", "an integer", reply)
+}
+
+func replyStrings(reply interface{}) ([]string, error) {
+	raw, err := replySlice(reply)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]string, len(raw))
+	for n, val := range raw {
+		if s, err := decodeString(elementPath("reply", n), val); err != nil {
+			return nil, err
+		} else {
+			results[n] = s
+		}
+	}
+	return results, nil
+}
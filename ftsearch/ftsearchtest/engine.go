@@ -0,0 +1,257 @@
+// Package ftsearchtest provides an in-process fake of a subset of
+// RediSearch so that code using ftsearch.Client can be tested without a
+// Redis server.
+//
+// The fake supports FT.CREATE, FT.SEARCH, FT.DROPINDEX and FT.INFO over
+// HASH and JSON documents, which are written with HSET, JSON.SET and DEL.
+// Queries may use terms, prefixes, phrases, field modifiers, TAG sets,
+// NUMERIC ranges, GEO radius ranges, GEOSHAPE predicates over points and
+// polygons (treated as flat), negation, unions, grouping and attribute
+// blocks, of which only $weight has an effect.
+// FT.SEARCH supports FILTER, GEOFILTER, INKEYS, INFIELDS, LIMIT, RETURN,
+// NOCONTENT, WITHSCORES, SORTBY and PARAMS; other arguments which change
+// the results are rejected rather than ignored. Documents are matched by
+// scanning rather than through an inverted index, stemming and stop words
+// are not applied and scores only approximate those of RediSearch - a
+// document scores the weighted number of matching term occurrences.
+package ftsearchtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+)
+
+// Engine is the fake. It is safe for concurrent use.
+type Engine struct {
+	mu      sync.Mutex
+	docs    map[string]*document
+	indexes map[string]*index
+}
+
+// document is a stored HASH or JSON document. Exactly one of hash and
+// json is set.
+type document struct {
+	hash map[string]string
+	json interface{}
+}
+
+// Error is an error returned by the fake in place of a reply. Messages
+// follow those of RediSearch.
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// RedisError marks the error as coming from the server, as go-redis does
+func (e Error) RedisError() {}
+
+var _ ftsearch.BatchExecutor = (*Engine)(nil)
+
+// New returns an empty engine
+func New() *Engine {
+	return &Engine{
+		docs:    make(map[string]*document),
+		indexes: make(map[string]*index),
+	}
+}
+
+// NewClient returns an empty engine and a client using it
+func NewClient() (*ftsearch.Client, *Engine) {
+	e := New()
+	return ftsearch.NewClientWithExecutor(e), e
+}
+
+// HSet stores a HASH document, adding to any fields already set
+func (e *Engine) HSet(key string, fields map[string]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hset(key, fields)
+}
+
+// JSONSet stores a JSON document, replacing any document at key. The
+// document is given as JSON text.
+func (e *Engine) JSONSet(key string, text string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.jsonSet(key, text)
+}
+
+// Del deletes documents, returning the number deleted
+func (e *Engine) Del(keys ...string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.del(keys)
+}
+
+// hset sets fields of a HASH document, returning the number of new fields
+func (e *Engine) hset(key string, fields map[string]string) int {
+	doc, ok := e.docs[key]
+	if !ok || doc.hash == nil {
+		doc = &document{hash: make(map[string]string, len(fields))}
+		e.docs[key] = doc
+	}
+
+	added := 0
+	for field, value := range fields {
+		if _, exists := doc.hash[field]; !exists {
+			added++
+		}
+		doc.hash[field] = value
+	}
+	return added
+}
+
+func (e *Engine) jsonSet(key string, text string) error {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return Error(fmt.Sprintf("ERR invalid JSON for %s: %v", key, err))
+	}
+	e.docs[key] = &document{json: value}
+	return nil
+}
+
+func (e *Engine) del(keys []string) int {
+	deleted := 0
+	for _, key := range keys {
+		if _, ok := e.docs[key]; ok {
+			delete(e.docs, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// Do runs a single command. Errors are returned as Error values and
+// replies have their RESP2 shapes.
+func (e *Engine) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	return e.do(2, args)
+}
+
+// do runs a single command, shaping the replies of FT.SEARCH and FT.INFO
+// for the protocol given. Only RESP3 replies contain maps.
+func (e *Engine) do(protocol int, args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, Error("ERR empty command")
+	}
+
+	tokens := ftsearch.FormatArgs(args)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch name := strings.ToUpper(tokens[0]); name {
+	case "FT.CREATE":
+		return e.create(tokens[1:])
+	case "FT.SEARCH":
+		return e.search(tokens[1:], protocol)
+	case "FT.DROPINDEX":
+		return e.dropIndex(tokens[1:])
+	case "FT.INFO":
+		return e.info(tokens[1:], protocol)
+	case "FT._LIST":
+		return e.list(), nil
+	case "HSET":
+		return e.hsetCommand(tokens[1:])
+	case "JSON.SET":
+		return e.jsonSetCommand(tokens[1:])
+	case "DEL":
+		if len(tokens) < 2 {
+			return nil, wrongArgs(name)
+		}
+		return int64(e.del(tokens[1:])), nil
+	default:
+		return nil, Error(fmt.Sprintf("ERR unknown command '%s'", tokens[0]))
+	}
+}
+
+// DoMulti runs each command in turn
+func (e *Engine) DoMulti(ctx context.Context, cmds ...[]interface{}) []ftsearch.Reply {
+	replies := make([]ftsearch.Reply, len(cmds))
+	for n, args := range cmds {
+		replies[n].Val, replies[n].Err = e.Do(ctx, args...)
+	}
+	return replies
+}
+
+// hsetCommand handles HSET key field value [field value...]
+func (e *Engine) hsetCommand(args []string) (interface{}, error) {
+	if len(args) < 3 || len(args)%2 != 1 {
+		return nil, wrongArgs("HSET")
+	}
+
+	fields := make(map[string]string, len(args)/2)
+	for n := 1; n < len(args); n += 2 {
+		fields[args[n]] = args[n+1]
+	}
+	return int64(e.hset(args[0], fields)), nil
+}
+
+// jsonSetCommand handles JSON.SET key $ json. Only whole documents can be
+// set.
+func (e *Engine) jsonSetCommand(args []string) (interface{}, error) {
+	if len(args) != 3 {
+		return nil, wrongArgs("JSON.SET")
+	}
+	if args[1] != "$" && args[1] != "." {
+		return nil, Error(fmt.Sprintf("ftsearchtest: unsupported JSON.SET path %s", args[1]))
+	}
+	if err := e.jsonSet(args[0], args[2]); err != nil {
+		return nil, err
+	}
+	return "OK", nil
+}
+
+func (e *Engine) dropIndex(args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, wrongArgs("FT.DROPINDEX")
+	}
+
+	idx, ok := e.indexes[args[0]]
+	if !ok {
+		return nil, Error("Unknown Index name")
+	}
+
+	if len(args) > 1 && strings.EqualFold(args[1], "DD") {
+		for _, key := range e.indexedKeys(idx) {
+			delete(e.docs, key)
+		}
+	}
+
+	delete(e.indexes, args[0])
+	return "OK", nil
+}
+
+func (e *Engine) list() []interface{} {
+	names := make([]string, 0, len(e.indexes))
+	for name := range e.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]interface{}, len(names))
+	for n, name := range names {
+		list[n] = name
+	}
+	return list
+}
+
+// indexedKeys returns the keys of the documents covered by an index, sorted
+func (e *Engine) indexedKeys(idx *index) []string {
+	var keys []string
+	for key, doc := range e.docs {
+		if idx.covers(key, doc) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func wrongArgs(command string) error {
+	return Error(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(command)))
+}
@@ -0,0 +1,252 @@
+package ftsearchtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/stretchr/testify/require"
+)
+
+func newBooks(t *testing.T) (*ftsearch.Client, *Engine) {
+	client, engine := NewClient()
+	create := ftsearch.NewCreate().WithIndex("books").
+		WithSchema(ftsearch.NewSchema().WithIdentifier("title").AttributeType("TEXT")).
+		WithSchema(ftsearch.NewSchema().WithIdentifier("genre").AttributeType("TAG")).
+		WithSchema(ftsearch.NewSchema().WithIdentifier("year").AttributeType("NUMERIC"))
+	_, err := client.CreateIndex(context.Background(), create)
+	require.NoError(t, err)
+
+	engine.HSet("book:1", map[string]string{"title": "The Go Programming Language", "genre": "tech,go", "year": "2015"})
+	engine.HSet("book:2", map[string]string{"title": "Learning Go", "genre": "tech", "year": "2021"})
+	engine.HSet("book:3", map[string]string{"title": "The Hobbit", "genre": "fantasy", "year": "1937"})
+	return client, engine
+}
+
+func TestSearchTerms(t *testing.T) {
+	client, _ := newBooks(t)
+	ctx := context.Background()
+
+	results, err := client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithQueryString("go"))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), results.Count)
+	require.Equal(t, "Learning Go", results.Data["book:2"].Value["title"])
+
+	results, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithQueryString("-go"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), results.Count)
+	require.Contains(t, results.Data, "book:3")
+
+	results, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithQueryString(`"the go" | hob*`))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), results.Count)
+	require.Contains(t, results.Data, "book:1")
+	require.Contains(t, results.Data, "book:3")
+}
+
+func TestSearchTagsAndFilters(t *testing.T) {
+	client, _ := newBooks(t)
+	ctx := context.Background()
+
+	results, err := client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithQueryString("@genre:{TECH}").
+		AddFilter(ftsearch.NewQueryFilter("year").WithMinExclusive(2015)))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), results.Count)
+	require.Contains(t, results.Data, "book:2")
+
+	results, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithQueryString("@year:[-inf (2000]"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), results.Count)
+	require.Contains(t, results.Data, "book:3")
+
+	results, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithDialect(2).
+		AddFilter(ftsearch.NewQueryFilter("year").WithMaxInclusive(2015)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"book:1", "book:3"}, results.Keys)
+
+	results, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithQueryString("go").
+		AddNumeric("year", ftsearch.NotEqual, 2015))
+	require.NoError(t, err)
+	require.Equal(t, []string{"book:2"}, results.Keys)
+}
+
+func TestSearchWeightedFields(t *testing.T) {
+	client, engine := NewClient()
+	ctx := context.Background()
+
+	_, err := client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex("posts").
+		WithSchema(ftsearch.NewSchema().WithIdentifier("title").AttributeType("TEXT")).
+		WithSchema(ftsearch.NewSchema().WithIdentifier("body").AttributeType("TEXT")))
+	require.NoError(t, err)
+	engine.HSet("post:1", map[string]string{"title": "Release notes", "body": "go go go"})
+	engine.HSet("post:2", map[string]string{"title": "Go generics", "body": "an overview"})
+
+	qry := ftsearch.NewQuery().WithIndex("posts").
+		AddWeightedFields("go", map[string]float64{"title": 5, "body": 1})
+	qry.WithScores = true
+	results, err := client.Search(ctx, qry)
+	require.NoError(t, err)
+	require.Equal(t, []string{"post:2", "post:1"}, results.Keys)
+	require.Equal(t, 5.0, results.Data["post:2"].Score)
+
+	_, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("posts").WithQueryString("(go) => { $boost: 2; }"))
+	require.Error(t, err)
+}
+
+func TestSearchOptions(t *testing.T) {
+	client, _ := newBooks(t)
+	ctx := context.Background()
+
+	qry := ftsearch.NewQuery().WithIndex("books").WithQueryString("*").
+		WithSortBy("year", false).WithLimit(0, 2).WithReturnFields([]string{"year"})
+	qry.WithScores = true
+	results, err := client.Search(ctx, qry)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), results.Count)
+	require.Len(t, results.Data, 2)
+	require.Equal(t, map[string]string{"year": "2021"}, results.Data["book:2"].Value)
+	require.Contains(t, results.Data, "book:1")
+
+	qry = ftsearch.NewQuery().WithIndex("books").WithQueryString("go")
+	qry.NoContent = true
+	results, err = client.Search(ctx, qry)
+	require.NoError(t, err)
+	require.Len(t, results.Data, 2)
+	require.Nil(t, results.Data["book:1"].Value)
+
+	_, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("missing").WithQueryString("*"))
+	require.EqualError(t, err, "missing: no such index")
+
+	qry = ftsearch.NewQuery().WithIndex("books").WithQueryString("*")
+	qry.ExplainScore = true
+	_, err = client.Search(ctx, qry)
+	require.Error(t, err)
+
+	qry = ftsearch.NewQuery().WithIndex("books").WithQueryString("go")
+	qry.Slop = 1
+	_, err = client.Search(ctx, qry)
+	require.EqualError(t, err, "ftsearchtest: unsupported FT.SEARCH argument SLOP")
+
+	qry = ftsearch.NewQuery().WithIndex("books").WithQueryString("go")
+	qry.Verbatim = true
+	_, err = client.Search(ctx, qry)
+	require.EqualError(t, err, "ftsearchtest: unsupported FT.SEARCH argument VERBATIM")
+}
+
+func TestSearchJSON(t *testing.T) {
+	client, engine := NewClient()
+	ctx := context.Background()
+
+	create := ftsearch.NewCreate().WithIndex("people").OnJSON().
+		WithSchema(ftsearch.NewSchema().WithIdentifier("$.name").AsAttribute("name").AttributeType("TEXT")).
+		WithSchema(ftsearch.NewSchema().WithIdentifier("$.roles[*]").AsAttribute("roles").AttributeType("TAG"))
+	_, err := client.CreateIndex(ctx, create)
+	require.NoError(t, err)
+
+	require.NoError(t, engine.JSONSet("person:1", `{"name":"Ada Lovelace","roles":["admin","author"]}`))
+	require.NoError(t, engine.JSONSet("person:2", `{"name":"Alan Turing","roles":["author"]}`))
+
+	results, err := client.Search(ctx, ftsearch.NewQuery().WithIndex("people").WithQueryString("@roles:{admin}"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), results.Count)
+	require.JSONEq(t, `{"name":"Ada Lovelace","roles":["admin","author"]}`, results.Data["person:1"].Value["$"])
+
+	results, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("people").WithQueryString("@name:turing").
+		WithReturnFields([]string{"name"}))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"name": "Alan Turing"}, results.Data["person:2"].Value)
+}
+
+func TestDropIndex(t *testing.T) {
+	client, engine := newBooks(t)
+	ctx := context.Background()
+
+	_, err := client.DropIndex(ctx, ftsearch.NewDropIndex().WithIndex("books").WithDD())
+	require.NoError(t, err)
+	require.Equal(t, 0, engine.Del("book:1"))
+
+	_, err = client.DropIndex(ctx, ftsearch.NewDropIndex().WithIndex("books"))
+	require.ErrorIs(t, err, ftsearch.ErrUnknownIndex)
+}
+
+func TestSearchGeo(t *testing.T) {
+	client, engine := NewClient()
+	ctx := context.Background()
+
+	_, err := client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex("stores").
+		WithSchema(ftsearch.NewSchema().WithIdentifier("location").AsGeo()))
+	require.NoError(t, err)
+	engine.HSet("store:trafalgar", map[string]string{"location": "-0.1281,51.5080"})
+	engine.HSet("store:greenwich", map[string]string{"location": "-0.0077,51.4826"})
+	engine.HSet("store:oxford", map[string]string{"location": "-1.2577,51.7520"})
+
+	results, err := client.Search(ctx, ftsearch.NewQuery().WithIndex("stores").WithQueryString("*").
+		AddGeoFilter("location", -0.1278, 51.5074, 1, ftsearch.Kilometers))
+	require.NoError(t, err)
+	require.Equal(t, []string{"store:trafalgar"}, results.Keys)
+
+	results, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("stores").
+		WithQueryString(ftsearch.GeoQuery("location", -0.1278, 51.5074, 10, ftsearch.Miles)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"store:greenwich", "store:trafalgar"}, results.Keys)
+}
+
+func TestSearchGeoShape(t *testing.T) {
+	client, engine := NewClient()
+	ctx := context.Background()
+
+	_, err := client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex("zones").
+		WithSchema(ftsearch.NewSchema().WithIdentifier("area").AsGeoShape(ftsearch.Flat)))
+	require.NoError(t, err)
+	engine.HSet("zone:centre", map[string]string{"area": rect(0, 0, 4, 4).WKT()})
+	engine.HSet("zone:east", map[string]string{"area": rect(10, 0, 14, 4).WKT()})
+	engine.HSet("zone:depot", map[string]string{"area": ftsearch.Point{X: 2, Y: 2}.WKT()})
+
+	search := func(predicate ftsearch.SpatialPredicate, shape ftsearch.Shape) []string {
+		results, err := client.Search(ctx, ftsearch.NewQuery().WithIndex("zones").AddGeoShape("area", predicate, shape))
+		require.NoError(t, err)
+		return results.Keys
+	}
+
+	require.Equal(t, []string{"zone:centre"}, search(ftsearch.Contains, ftsearch.Point{X: 1, Y: 3}))
+	require.Equal(t, []string{"zone:depot"}, search(ftsearch.Within, rect(1, 1, 3, 3)))
+	require.Equal(t, []string{"zone:centre", "zone:east"}, search(ftsearch.Intersects, rect(3, 1, 11, 2)))
+	require.Equal(t, []string{"zone:east"}, search(ftsearch.Disjoint, rect(-1, -1, 5, 5)))
+}
+
+// rect returns the rectangle with corners (x0 y0) and (x1 y1)
+func rect(x0, y0, x1, y1 float64) ftsearch.Polygon {
+	return ftsearch.Polygon{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+}
+
+func TestRESP3Replies(t *testing.T) {
+	_, engine := newBooks(t)
+
+	reply, err := engine.do(3, []interface{}{"FT.SEARCH", "books", "hobbit", "WITHSCORES", "RETURN", "1", "year"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"attributes":    []interface{}{},
+		"format":        "STRING",
+		"total_results": int64(1),
+		"warning":       []interface{}{},
+		"results": []interface{}{
+			map[string]interface{}{
+				"id":               "book:3",
+				"score":            1.0,
+				"extra_attributes": map[string]interface{}{"year": "1937"},
+				"values":           []interface{}{},
+			},
+		},
+	}, reply)
+
+	reply, err = engine.do(3, []interface{}{"FT.INFO", "books"})
+	require.NoError(t, err)
+	info := reply.(map[string]interface{})
+	require.Equal(t, "books", info["index_name"])
+	require.Equal(t, "HASH", info["index_definition"].(map[string]interface{})["key_type"])
+	require.Equal(t, "genre", info["attributes"].([]interface{})[1].(map[string]interface{})["identifier"])
+
+	reply, err = engine.Do(context.Background(), "FT.INFO", "books")
+	require.NoError(t, err)
+	require.Equal(t, "index_name", reply.([]interface{})[0])
+}
@@ -0,0 +1,816 @@
+package ftsearchtest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// node is a parsed query expression. match reports whether the document
+// matches and its score; scope lists the TEXT fields unscoped terms are
+// matched against, nil meaning all of them.
+type node interface {
+	match(d *docView, scope []string) (bool, float64)
+}
+
+type (
+	allNode       struct{}
+	notNode       struct{ child node }
+	optionalNode  struct{ child node }
+	intersectNode struct{ children []node }
+	unionNode     struct{ children []node }
+	scopeNode     struct {
+		fields []string
+		child  node
+	}
+	termNode struct {
+		term   string
+		prefix bool
+	}
+	phraseNode struct{ terms []string }
+	tagNode    struct {
+		fields []string
+		values []string
+	}
+	numericNode struct {
+		fields []string
+		r      numericRange
+	}
+	geoNode struct {
+		fields []string
+		area   geoArea
+	}
+	shapeNode struct {
+		fields    []string
+		predicate string
+		shape     shape
+	}
+	weightNode struct {
+		child  node
+		weight float64
+	}
+)
+
+// numericRange is a range of numbers with optionally exclusive bounds
+type numericRange struct {
+	min, max       float64
+	minExc, maxExc bool
+}
+
+// docView holds the indexed values of a single document
+type docView struct {
+	key     string
+	text    map[string][]string // TEXT field tokens
+	weights map[string]float64
+	tags    map[string][]string
+	numbers map[string][]float64
+	points  map[string][]geoPoint
+	shapes  map[string][]shape
+}
+
+// parser is a recursive descent parser for the supported query syntax
+type parser struct {
+	input  []rune
+	pos    int
+	params map[string]string
+}
+
+// parseQuery parses a query string, substituting $name parameters
+func parseQuery(query string, params map[string]string) (node, error) {
+	p := &parser{input: []rune(query), params: params}
+	n, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected '%c'", p.input[p.pos])
+	}
+	return n, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return Error(fmt.Sprintf("Syntax error at offset %d near %s", p.pos, fmt.Sprintf(format, args...)))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() rune {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) parseUnion() (node, error) {
+	var children []node
+	for {
+		child, err := p.parseIntersect()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+
+		p.skipSpace()
+		if p.peek() != '|' {
+			break
+		}
+		p.pos++
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &unionNode{children: children}, nil
+}
+
+func (p *parser) parseIntersect() (node, error) {
+	var children []node
+	for {
+		p.skipSpace()
+		if c := p.peek(); c == 0 || c == ')' || c == '|' {
+			break
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	switch len(children) {
+	case 0:
+		return nil, p.errorf("empty expression")
+	case 1:
+		return children[0], nil
+	default:
+		return &intersectNode{children: children}, nil
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		child, err := p.parseUnary()
+		return &notNode{child: child}, err
+	case '~':
+		p.pos++
+		child, err := p.parseUnary()
+		return &optionalNode{child: child}, err
+	default:
+		child, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		return p.parseAttributes(child)
+	}
+}
+
+// parseAttributes parses an optional => { $name: value; } block after an
+// expression. $weight scales the score; $slop, $inorder and $phonetic are
+// accepted but ignored.
+func (p *parser) parseAttributes(child node) (node, error) {
+	start := p.pos
+	p.skipSpace()
+	if !strings.HasPrefix(string(p.input[p.pos:]), "=>") {
+		p.pos = start
+		return child, nil
+	}
+	p.pos += 2
+	if p.skipSpace(); p.peek() != '{' {
+		return nil, p.errorf("missing '{' after '=>'")
+	}
+	open := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] != '}' {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("missing '}'")
+	}
+	body := string(p.input[open+1 : p.pos])
+	p.pos++
+
+	weighted := &weightNode{child: child, weight: 1}
+	for _, attr := range strings.Split(body, ";") {
+		if strings.TrimSpace(attr) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(attr, ":")
+		if !ok {
+			return nil, p.errorf("bad attribute %s", strings.TrimSpace(attr))
+		}
+		value = p.param(strings.TrimSpace(value))
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "$weight":
+			weight, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, p.errorf("bad weight %s", value)
+			}
+			weighted.weight = weight
+		case "$slop", "$inorder", "$phonetic":
+		default:
+			return nil, p.errorf("unknown attribute %s", strings.TrimSpace(name))
+		}
+	}
+	return weighted, nil
+}
+
+func (p *parser) parseAtom() (node, error) {
+	switch p.peek() {
+	case '(':
+		p.pos++
+		child, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.peek() != ')' {
+			return nil, p.errorf("missing ')'")
+		}
+		p.pos++
+		return child, nil
+	case '@':
+		return p.parseField()
+	case '"':
+		return p.parsePhrase()
+	case '*':
+		p.pos++
+		return &allNode{}, nil
+	default:
+		return p.parseWord()
+	}
+}
+
+// parseField parses @field:expression, where the expression may be a tag
+// set, a numeric or geo range or a text expression scoped to the fields
+func (p *parser) parseField() (node, error) {
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] != ':' {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("missing ':' after field name")
+	}
+	fields := strings.Split(string(p.input[start:p.pos]), "|")
+	p.pos++
+	p.skipSpace()
+
+	switch p.peek() {
+	case '{':
+		values, err := p.parseTags()
+		return &tagNode{fields: fields, values: values}, err
+	case '[':
+		return p.parseRange(fields)
+	case '-':
+		p.pos++
+		child, err := p.parseAtom()
+		return &scopeNode{fields: fields, child: &notNode{child: child}}, err
+	default:
+		child, err := p.parseAtom()
+		return &scopeNode{fields: fields, child: child}, err
+	}
+}
+
+// parseTags parses {value | value...}, where values may use backslash
+// escapes and end in * for a prefix match
+func (p *parser) parseTags() ([]string, error) {
+	p.pos++
+	var values []string
+	var current strings.Builder
+	for {
+		if p.pos >= len(p.input) {
+			return nil, p.errorf("missing '}'")
+		}
+		c := p.input[p.pos]
+		p.pos++
+		switch c {
+		case '\\':
+			if p.pos < len(p.input) {
+				current.WriteRune(p.input[p.pos])
+				p.pos++
+			}
+		case '|', '}':
+			values = append(values, p.param(strings.TrimSpace(current.String())))
+			current.Reset()
+			if c == '}' {
+				return values, nil
+			}
+		default:
+			current.WriteRune(c)
+		}
+	}
+}
+
+// parseRange parses [min max] or [value] for a numeric range, [lon lat radius unit]
+// for a geo area or [predicate shape] for a GEOSHAPE query
+func (p *parser) parseRange(fields []string) (node, error) {
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] != ']' {
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("missing ']'")
+	}
+	parts := strings.Fields(string(p.input[start:p.pos]))
+	p.pos++
+
+	for n := range parts {
+		parts[n] = p.param(parts[n])
+	}
+	if len(parts) == 4 {
+		area, err := parseGeoArea(parts)
+		if err != nil {
+			return nil, err
+		}
+		return &geoNode{fields: fields, area: area}, nil
+	}
+	if len(parts) == 1 {
+		parts = append(parts, parts[0])
+	}
+	if len(parts) != 2 {
+		return nil, p.errorf("unsupported range with %d values", len(parts))
+	}
+	if predicate := strings.ToUpper(parts[0]); spatialPredicates[predicate] {
+		s, ok := parseWKT(parts[1])
+		if !ok {
+			return nil, Error(fmt.Sprintf("Invalid WKT: %s", parts[1]))
+		}
+		return &shapeNode{fields: fields, predicate: predicate, shape: s}, nil
+	}
+	r, err := parseNumericRange(parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return &numericNode{fields: fields, r: r}, nil
+}
+
+func (p *parser) parsePhrase() (node, error) {
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] != '"' {
+		if p.input[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("missing '\"'")
+	}
+	terms := tokenize(p.param(string(p.input[start:p.pos])))
+	p.pos++
+	return &phraseNode{terms: terms}, nil
+}
+
+// parseWord parses a bare term, which is tokenized the same way as
+// document text. A trailing * makes it a prefix.
+func (p *parser) parseWord() (node, error) {
+	var word strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '\\' && p.pos+1 < len(p.input) {
+			word.WriteRune(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if unicode.IsSpace(c) || strings.ContainsRune("()|{}[]@\"~", c) {
+			break
+		}
+		word.WriteRune(c)
+		p.pos++
+	}
+	if word.Len() == 0 {
+		return nil, p.errorf("unexpected '%c'", p.peek())
+	}
+
+	text := p.param(word.String())
+	prefix := strings.HasSuffix(text, "*")
+	terms := tokenize(strings.TrimSuffix(text, "*"))
+	switch {
+	case len(terms) == 0:
+		return &allNode{}, nil
+	case len(terms) == 1:
+		return &termNode{term: terms[0], prefix: prefix}, nil
+	default:
+		return &phraseNode{terms: terms}, nil
+	}
+}
+
+// param substitutes the value of a $name parameter
+func (p *parser) param(text string) string {
+	if strings.HasPrefix(text, "$") {
+		if value, ok := p.params[text[1:]]; ok {
+			return value
+		}
+	}
+	return text
+}
+
+// parseNumericRange parses range bounds, which may be exclusive (prefixed
+// with a '(') or infinite
+func parseNumericRange(min string, max string) (numericRange, error) {
+	var r numericRange
+	var err error
+	if r.min, r.minExc, err = parseBound(min); err != nil {
+		return r, err
+	}
+	if r.max, r.maxExc, err = parseBound(max); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+func parseBound(bound string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(bound, "(")
+	bound = strings.TrimPrefix(bound, "(")
+	switch strings.ToLower(bound) {
+	case "inf", "+inf":
+		return math.Inf(1), exclusive, nil
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	}
+	value, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return 0, false, Error(fmt.Sprintf("Bad upper range: %s", bound))
+	}
+	return value, exclusive, nil
+}
+
+func (r numericRange) contains(value float64) bool {
+	if value < r.min || r.minExc && value == r.min {
+		return false
+	}
+	if value > r.max || r.maxExc && value == r.max {
+		return false
+	}
+	return true
+}
+
+// tokenize splits text into lower case terms on anything other than
+// letters, digits and underscores
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_'
+	})
+}
+
+/******************************************************************************
+* Matching                                                                    *
+******************************************************************************/
+
+func (n *allNode) match(d *docView, scope []string) (bool, float64) {
+	return true, 0
+}
+
+func (n *notNode) match(d *docView, scope []string) (bool, float64) {
+	matched, _ := n.child.match(d, scope)
+	return !matched, 0
+}
+
+func (n *optionalNode) match(d *docView, scope []string) (bool, float64) {
+	_, score := n.child.match(d, scope)
+	return true, score
+}
+
+func (n *intersectNode) match(d *docView, scope []string) (bool, float64) {
+	total := 0.0
+	for _, child := range n.children {
+		matched, score := child.match(d, scope)
+		if !matched {
+			return false, 0
+		}
+		total += score
+	}
+	return true, total
+}
+
+func (n *unionNode) match(d *docView, scope []string) (bool, float64) {
+	any, total := false, 0.0
+	for _, child := range n.children {
+		if matched, score := child.match(d, scope); matched {
+			any = true
+			total += score
+		}
+	}
+	return any, total
+}
+
+func (n *weightNode) match(d *docView, scope []string) (bool, float64) {
+	matched, score := n.child.match(d, scope)
+	return matched, score * n.weight
+}
+
+func (n *scopeNode) match(d *docView, scope []string) (bool, float64) {
+	return n.child.match(d, n.fields)
+}
+
+func (n *termNode) match(d *docView, scope []string) (bool, float64) {
+	score := 0.0
+	for _, name := range d.textFields(scope) {
+		for _, token := range d.text[name] {
+			if token == n.term || n.prefix && strings.HasPrefix(token, n.term) {
+				score += d.weights[name]
+			}
+		}
+	}
+	return score > 0, score
+}
+
+func (n *phraseNode) match(d *docView, scope []string) (bool, float64) {
+	score := 0.0
+	for _, name := range d.textFields(scope) {
+		tokens := d.text[name]
+		for start := 0; start+len(n.terms) <= len(tokens); start++ {
+			found := true
+			for offset, term := range n.terms {
+				if tokens[start+offset] != term {
+					found = false
+					break
+				}
+			}
+			if found {
+				score += d.weights[name] * float64(len(n.terms))
+			}
+		}
+	}
+	return score > 0, score
+}
+
+func (n *tagNode) match(d *docView, scope []string) (bool, float64) {
+	for _, name := range n.fields {
+		for _, tag := range d.tags[name] {
+			for _, value := range n.values {
+				if tagMatches(tag, value) {
+					return true, 0
+				}
+			}
+		}
+	}
+	return false, 0
+}
+
+func (n *geoNode) match(d *docView, scope []string) (bool, float64) {
+	for _, name := range n.fields {
+		for _, point := range d.points[name] {
+			if n.area.contains(point) {
+				return true, 0
+			}
+		}
+	}
+	return false, 0
+}
+
+func (n *shapeNode) match(d *docView, scope []string) (bool, float64) {
+	for _, name := range n.fields {
+		for _, s := range d.shapes[name] {
+			if n.relates(s) {
+				return true, 0
+			}
+		}
+	}
+	return false, 0
+}
+
+// relates tests the predicate between a document shape and the query shape
+func (n *shapeNode) relates(s shape) bool {
+	switch n.predicate {
+	case "WITHIN":
+		return n.shape.contains(s)
+	case "CONTAINS":
+		return s.contains(n.shape)
+	case "INTERSECTS":
+		return s.intersects(n.shape)
+	default:
+		return !s.intersects(n.shape)
+	}
+}
+
+func (n *numericNode) match(d *docView, scope []string) (bool, float64) {
+	for _, name := range n.fields {
+		for _, value := range d.numbers[name] {
+			if n.r.contains(value) {
+				return true, 0
+			}
+		}
+	}
+	return false, 0
+}
+
+// tagMatches compares a document tag with a query value. Tags are stored
+// lower case unless the field is case sensitive so the value is compared
+// both ways.
+func tagMatches(tag string, value string) bool {
+	if strings.HasSuffix(value, "*") {
+		prefix := strings.TrimSuffix(value, "*")
+		return strings.HasPrefix(tag, prefix) || strings.HasPrefix(tag, strings.ToLower(prefix))
+	}
+	return tag == value || tag == strings.ToLower(value)
+}
+
+// textFields returns the TEXT fields searched for the scope given
+func (d *docView) textFields(scope []string) []string {
+	if scope != nil {
+		return scope
+	}
+	names := make([]string, 0, len(d.text))
+	for name := range d.text {
+		names = append(names, name)
+	}
+	return names
+}
+
+/******************************************************************************
+* Geo                                                                         *
+******************************************************************************/
+
+// geoPoint is a position held by a GEO field
+type geoPoint struct {
+	lon, lat float64
+}
+
+// geoArea is a circle given to GEOFILTER or a geo query
+type geoArea struct {
+	centre geoPoint
+	radius float64 // meters
+}
+
+// earthRadius is the radius Redis uses for geo distances, in meters
+const earthRadius = 6372797.560856
+
+var geoUnits = map[string]float64{"m": 1, "km": 1000, "mi": 1609.34, "ft": 0.3048}
+
+// parseGeoPoint parses a "lon,lat" GEO field value
+func parseGeoPoint(value string) (geoPoint, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return geoPoint{}, false
+	}
+	lon, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lat, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	return geoPoint{lon: lon, lat: lat}, err1 == nil && err2 == nil
+}
+
+// parseGeoArea parses lon lat radius unit
+func parseGeoArea(args []string) (geoArea, error) {
+	var values [3]float64
+	for n := range values {
+		value, err := strconv.ParseFloat(args[n], 64)
+		if err != nil {
+			return geoArea{}, Error(fmt.Sprintf("Invalid GeoFilter value %s", args[n]))
+		}
+		values[n] = value
+	}
+	scale, ok := geoUnits[strings.ToLower(args[3])]
+	if !ok {
+		return geoArea{}, Error(fmt.Sprintf("Invalid GeoFilter unit %s", args[3]))
+	}
+	return geoArea{centre: geoPoint{lon: values[0], lat: values[1]}, radius: values[2] * scale}, nil
+}
+
+func (a geoArea) contains(point geoPoint) bool {
+	return distance(a.centre, point) <= a.radius
+}
+
+// distance returns the haversine distance between two points in meters
+func distance(from geoPoint, to geoPoint) float64 {
+	lat1, lat2 := from.lat*math.Pi/180, to.lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (to.lon - from.lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+/******************************************************************************
+* GEOSHAPE                                                                    *
+******************************************************************************/
+
+// shape is a WKT point or polygon. Polygons hold their outer ring without
+// the closing point; holes are ignored. Both coordinate systems are
+// treated as flat.
+type shape struct {
+	points  []geoPoint
+	polygon bool
+}
+
+var spatialPredicates = map[string]bool{"WITHIN": true, "CONTAINS": true, "INTERSECTS": true, "DISJOINT": true}
+
+// parseWKT parses POINT (x y) and POLYGON ((x y, ...))
+func parseWKT(text string) (shape, bool) {
+	open := strings.Index(text, "(")
+	if open < 0 {
+		return shape{}, false
+	}
+	kind := strings.ToUpper(strings.TrimSpace(text[:open]))
+	body := strings.Trim(text[open:], "() ")
+	if end := strings.Index(body, ")"); end >= 0 {
+		body = body[:end]
+	}
+
+	var s shape
+	for _, pair := range strings.Split(body, ",") {
+		coordinates := strings.Fields(pair)
+		if len(coordinates) != 2 {
+			return shape{}, false
+		}
+		x, err1 := strconv.ParseFloat(coordinates[0], 64)
+		y, err2 := strconv.ParseFloat(coordinates[1], 64)
+		if err1 != nil || err2 != nil {
+			return shape{}, false
+		}
+		s.points = append(s.points, geoPoint{lon: x, lat: y})
+	}
+
+	switch kind {
+	case "POINT":
+		return s, len(s.points) == 1
+	case "POLYGON":
+		s.polygon = true
+		if n := len(s.points); n > 1 && s.points[0] == s.points[n-1] {
+			s.points = s.points[:n-1]
+		}
+		return s, len(s.points) >= 3
+	default:
+		return shape{}, false
+	}
+}
+
+// contains checks that every vertex of other lies in s. For polygons this
+// only approximates containment but is exact for convex ones.
+func (s shape) contains(other shape) bool {
+	if !s.polygon {
+		return !other.polygon && s.points[0] == other.points[0]
+	}
+	for _, point := range other.points {
+		if !s.containsPoint(point) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s shape) intersects(other shape) bool {
+	for _, point := range other.points {
+		if s.containsPoint(point) {
+			return true
+		}
+	}
+	for _, point := range s.points {
+		if other.containsPoint(point) {
+			return true
+		}
+	}
+	for _, a := range s.edges() {
+		for _, b := range other.edges() {
+			if segmentsCross(a[0], a[1], b[0], b[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsPoint tests a point against a polygon by ray casting, or for
+// equality with a point
+func (s shape) containsPoint(point geoPoint) bool {
+	if !s.polygon {
+		return s.points[0] == point
+	}
+
+	inside := false
+	for i, j := 0, len(s.points)-1; i < len(s.points); j, i = i, i+1 {
+		a, b := s.points[i], s.points[j]
+		if (a.lat > point.lat) != (b.lat > point.lat) &&
+			point.lon < (b.lon-a.lon)*(point.lat-a.lat)/(b.lat-a.lat)+a.lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (s shape) edges() [][2]geoPoint {
+	if !s.polygon {
+		return nil
+	}
+	edges := make([][2]geoPoint, len(s.points))
+	for i := range s.points {
+		edges[i] = [2]geoPoint{s.points[i], s.points[(i+1)%len(s.points)]}
+	}
+	return edges
+}
+
+// segmentsCross checks whether segments ab and cd properly cross
+func segmentsCross(a, b, c, d geoPoint) bool {
+	cross := func(o, p, q geoPoint) float64 {
+		return (p.lon-o.lon)*(q.lat-o.lat) - (p.lat-o.lat)*(q.lon-o.lon)
+	}
+	d1, d2 := cross(c, d, a), cross(c, d, b)
+	d3, d4 := cross(a, b, c), cross(a, b, d)
+	return (d1 > 0) != (d2 > 0) && (d3 > 0) != (d4 > 0) && d1 != 0 && d2 != 0 && d3 != 0 && d4 != 0
+}
@@ -0,0 +1,358 @@
+package ftsearchtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// index is an index created by FT.CREATE
+type index struct {
+	name     string
+	on       string
+	prefixes []string
+	fields   []*field
+}
+
+// field is a single attribute of an index schema
+type field struct {
+	identifier    string
+	name          string
+	kind          string
+	weight        float64
+	separator     string
+	caseSensitive bool
+	sortable      bool
+	noIndex       bool
+}
+
+const (
+	onHash = "HASH"
+	onJSON = "JSON"
+
+	kindText    = "TEXT"
+	kindTag     = "TAG"
+	kindNumeric = "NUMERIC"
+	kindGeo     = "GEO"
+	kindShape   = "GEOSHAPE"
+)
+
+// create handles FT.CREATE index [ON HASH|JSON] [PREFIX n prefix...] SCHEMA ...
+func (e *Engine) create(args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, wrongArgs("FT.CREATE")
+	}
+	if _, exists := e.indexes[args[0]]; exists {
+		return nil, Error("Index already exists")
+	}
+
+	idx := &index{name: args[0], on: onHash}
+	pos := 1
+	for pos < len(args) && !strings.EqualFold(args[pos], "SCHEMA") {
+		switch strings.ToUpper(args[pos]) {
+		case "ON":
+			if pos+1 >= len(args) {
+				return nil, Error("Missing argument for ON")
+			}
+			idx.on = strings.ToUpper(args[pos+1])
+			if idx.on != onHash && idx.on != onJSON {
+				return nil, Error(fmt.Sprintf("Invalid ON argument: %s", args[pos+1]))
+			}
+			pos += 2
+		case "PREFIX":
+			count, err := countArg(args, pos+1)
+			if err != nil {
+				return nil, err
+			}
+			idx.prefixes = append(idx.prefixes, args[pos+2:pos+2+count]...)
+			pos += 2 + count
+		case "LANGUAGE", "LANGUAGE_FIELD", "SCORE", "SCORE_FIELD", "PAYLOAD_FIELD", "TEMPORARY":
+			pos += 2
+		case "MAXTEXTFIELDS", "NOOFFSETS", "NOHL", "NOFIELDS", "NOFREQS", "SKIPINITIALSCAN":
+			pos++
+		default:
+			return nil, Error(fmt.Sprintf("ftsearchtest: unsupported FT.CREATE argument %s", args[pos]))
+		}
+	}
+
+	if pos >= len(args) {
+		return nil, Error("No schema found")
+	}
+
+	fields, err := parseSchema(args[pos+1:])
+	if err != nil {
+		return nil, err
+	}
+	idx.fields = fields
+
+	e.indexes[idx.name] = idx
+	return "OK", nil
+}
+
+// parseSchema parses the field definitions following SCHEMA
+func parseSchema(args []string) ([]*field, error) {
+	var fields []*field
+	pos := 0
+	for pos < len(args) {
+		f := &field{identifier: args[pos], weight: 1, separator: ","}
+		f.name = f.identifier
+		pos++
+
+		if pos+1 < len(args) && strings.EqualFold(args[pos], "AS") {
+			f.name = args[pos+1]
+			pos += 2
+		}
+
+		if pos >= len(args) {
+			return nil, Error(fmt.Sprintf("Field `%s` does not have a type", f.identifier))
+		}
+		f.kind = strings.ToUpper(args[pos])
+		switch f.kind {
+		case kindText, kindTag, kindNumeric, kindGeo, kindShape:
+		default:
+			return nil, Error(fmt.Sprintf("ftsearchtest: unsupported field type %s", args[pos]))
+		}
+		pos++
+
+	options:
+		for pos < len(args) {
+			switch strings.ToUpper(args[pos]) {
+			case "SORTABLE":
+				f.sortable = true
+				pos++
+			case "UNF", "NOSTEM", "WITHSUFFIXTRIE", "INDEXEMPTY", "INDEXMISSING", "FLAT", "SPHERICAL":
+				pos++
+			case "NOINDEX":
+				f.noIndex = true
+				pos++
+			case "CASESENSITIVE":
+				f.caseSensitive = true
+				pos++
+			case "WEIGHT":
+				if pos+1 >= len(args) {
+					return nil, Error("Missing argument for WEIGHT")
+				}
+				weight, err := strconv.ParseFloat(args[pos+1], 64)
+				if err != nil {
+					return nil, Error("Bad argument for WEIGHT")
+				}
+				f.weight = weight
+				pos += 2
+			case "SEPARATOR":
+				if pos+1 >= len(args) {
+					return nil, Error("Missing argument for SEPARATOR")
+				}
+				f.separator = args[pos+1]
+				pos += 2
+			case "PHONETIC":
+				pos += 2
+			default:
+				break options
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	if len(fields) == 0 {
+		return nil, Error("No schema found")
+	}
+	return fields, nil
+}
+
+// info handles FT.INFO, returning a subset of the RediSearch reply
+func (e *Engine) info(args []string, protocol int) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, wrongArgs("FT.INFO")
+	}
+	idx, ok := e.indexes[args[0]]
+	if !ok {
+		return nil, Error("Unknown Index name")
+	}
+
+	prefixes := []interface{}{}
+	for _, prefix := range idx.prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	if len(prefixes) == 0 {
+		prefixes = append(prefixes, "")
+	}
+
+	attributes := make([]interface{}, len(idx.fields))
+	for n, f := range idx.fields {
+		attribute := []interface{}{"identifier", f.identifier, "attribute", f.name, "type", f.kind}
+		if f.kind == kindText {
+			attribute = append(attribute, "WEIGHT", strconv.FormatFloat(f.weight, 'g', -1, 64))
+		}
+		if f.kind == kindTag {
+			attribute = append(attribute, "SEPARATOR", f.separator)
+		}
+		if protocol == 3 {
+			flags := []interface{}{}
+			if f.sortable {
+				flags = append(flags, "SORTABLE")
+			}
+			attributes[n] = pairMap(append(attribute, "flags", flags))
+		} else {
+			if f.sortable {
+				attribute = append(attribute, "SORTABLE")
+			}
+			attributes[n] = attribute
+		}
+	}
+
+	definition := []interface{}{"key_type", idx.on, "prefixes", prefixes, "default_score", "1"}
+	reply := []interface{}{
+		"index_name", idx.name,
+		"index_options", []interface{}{},
+		"index_definition", definition,
+		"attributes", attributes,
+		"num_docs", strconv.Itoa(len(e.indexedKeys(idx))),
+	}
+	if protocol == 3 {
+		reply[5] = pairMap(definition)
+		return pairMap(reply), nil
+	}
+	return reply, nil
+}
+
+// pairMap converts a flat list of names and values into a map, as RESP3
+// sends them
+func pairMap(pairs []interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		m[fmt.Sprint(pairs[i])] = pairs[i+1]
+	}
+	return m
+}
+
+// covers checks whether a document is indexed by the index
+func (idx *index) covers(key string, doc *document) bool {
+	if idx.on == onJSON && doc.json == nil || idx.on == onHash && doc.hash == nil {
+		return false
+	}
+	if len(idx.prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range idx.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the schema field with the name or identifier given
+func (idx *index) field(name string) *field {
+	name = strings.TrimPrefix(name, "@")
+	for _, f := range idx.fields {
+		if f.name == name || f.identifier == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// values returns the raw values of a field in a document as strings.
+// JSON paths may return several values; JSON arrays are flattened.
+func (f *field) values(doc *document) []string {
+	if doc.hash != nil {
+		if value, ok := doc.hash[f.identifier]; ok {
+			return []string{value}
+		}
+		return nil
+	}
+
+	var values []string
+	for _, value := range evalPath(doc.json, f.identifier) {
+		if array, ok := value.([]interface{}); ok {
+			for _, item := range array {
+				values = append(values, jsonString(item))
+			}
+		} else if value != nil {
+			values = append(values, jsonString(value))
+		}
+	}
+	return values
+}
+
+// jsonString formats a JSON value the way RediSearch returns it - strings
+// as they are and anything else as JSON text
+func jsonString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+// evalPath evaluates the subset of JSONPath used in schemas: $, dotted
+// member names, [n] indexes and [*] wildcards. Paths without a leading
+// $ are treated as member names.
+func evalPath(root interface{}, path string) []interface{} {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := []interface{}{root}
+	if path == "" {
+		return current
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		name := segment
+		var selectors []string
+		if open := strings.Index(segment, "["); open >= 0 {
+			name = segment[:open]
+			for _, part := range strings.Split(segment[open+1:], "[") {
+				selectors = append(selectors, strings.TrimSuffix(part, "]"))
+			}
+		}
+
+		var next []interface{}
+		for _, value := range current {
+			if name != "" {
+				object, ok := value.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if value, ok = object[name]; !ok {
+					continue
+				}
+			}
+			next = append(next, applySelectors(value, selectors)...)
+		}
+		current = next
+	}
+	return current
+}
+
+func applySelectors(value interface{}, selectors []string) []interface{} {
+	values := []interface{}{value}
+	for _, selector := range selectors {
+		var next []interface{}
+		for _, value := range values {
+			array, ok := value.([]interface{})
+			if !ok {
+				continue
+			}
+			if selector == "*" {
+				next = append(next, array...)
+			} else if n, err := strconv.Atoi(selector); err == nil && n >= 0 && n < len(array) {
+				next = append(next, array[n])
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// countArg reads the count at pos and checks enough arguments follow it
+func countArg(args []string, pos int) (int, error) {
+	if pos >= len(args) {
+		return 0, Error("Missing argument count")
+	}
+	count, err := strconv.Atoi(args[pos])
+	if err != nil || count < 0 || pos+1+count > len(args) {
+		return 0, Error(fmt.Sprintf("Bad arguments for %s: expected an argument count", args[pos-1]))
+	}
+	return count, nil
+}
@@ -0,0 +1,423 @@
+package ftsearchtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// searchArgs holds the parsed arguments of an FT.SEARCH
+type searchArgs struct {
+	index      string
+	query      string
+	noContent  bool
+	withScores bool
+	filters    []numericFilter
+	geoFilters []geoFilter
+	inKeys     map[string]bool
+	inFields   []string
+	returns    [][2]string // attribute and the name to return it as
+	sortBy     string
+	ascending  bool
+	offset     int
+	num        int
+	params     map[string]string
+}
+
+type numericFilter struct {
+	field string
+	r     numericRange
+}
+
+type geoFilter struct {
+	field string
+	area  geoArea
+}
+
+// hit is a matching document
+type hit struct {
+	key   string
+	score float64
+	doc   *document
+}
+
+// search handles FT.SEARCH
+func (e *Engine) search(args []string, protocol int) (interface{}, error) {
+	sa, err := parseSearchArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, ok := e.indexes[sa.index]
+	if !ok {
+		return nil, Error(fmt.Sprintf("%s: no such index", sa.index))
+	}
+
+	root, err := parseQuery(sa.query, sa.params)
+	if err != nil {
+		return nil, err
+	}
+
+	var scope []string
+	for _, name := range sa.inFields {
+		scope = append(scope, strings.TrimPrefix(name, "@"))
+	}
+
+	var hits []hit
+	for _, key := range e.indexedKeys(idx) {
+		if sa.inKeys != nil && !sa.inKeys[key] {
+			continue
+		}
+		doc := e.docs[key]
+		view := idx.view(key, doc)
+		if !sa.passesFilters(view) {
+			continue
+		}
+		if matched, score := root.match(view, scope); matched {
+			hits = append(hits, hit{key: key, score: score, doc: doc})
+		}
+	}
+
+	if err := sa.sort(idx, hits); err != nil {
+		return nil, err
+	}
+
+	if protocol == 3 {
+		return sa.resultMap(idx, hits), nil
+	}
+
+	reply := []interface{}{int64(len(hits))}
+	for n := sa.offset; n < len(hits) && n < sa.offset+sa.num; n++ {
+		h := hits[n]
+		reply = append(reply, h.key)
+		if sa.withScores {
+			reply = append(reply, strconv.FormatFloat(h.score, 'g', -1, 64))
+		}
+		if !sa.noContent {
+			reply = append(reply, sa.content(idx, h.doc))
+		}
+	}
+	return reply, nil
+}
+
+// parseSearchArgs parses the arguments of FT.SEARCH. Arguments which
+// change the results but are not supported are rejected.
+func parseSearchArgs(args []string) (*searchArgs, error) {
+	if len(args) < 2 {
+		return nil, wrongArgs("FT.SEARCH")
+	}
+
+	sa := &searchArgs{index: args[0], query: args[1], ascending: true, num: 10}
+	pos := 2
+	for pos < len(args) {
+		switch strings.ToUpper(args[pos]) {
+		case "NOCONTENT":
+			sa.noContent = true
+			pos++
+		case "WITHSCORES":
+			sa.withScores = true
+			pos++
+		case "TIMEOUT", "DIALECT":
+			pos += 2
+		case "FILTER":
+			if pos+3 >= len(args) {
+				return nil, Error("Bad arguments for FILTER")
+			}
+			r, err := parseNumericRange(args[pos+2], args[pos+3])
+			if err != nil {
+				return nil, err
+			}
+			sa.filters = append(sa.filters, numericFilter{field: strings.TrimPrefix(args[pos+1], "@"), r: r})
+			pos += 4
+		case "GEOFILTER":
+			if pos+5 >= len(args) {
+				return nil, Error("Bad arguments for GEOFILTER")
+			}
+			area, err := parseGeoArea(args[pos+2 : pos+6])
+			if err != nil {
+				return nil, err
+			}
+			sa.geoFilters = append(sa.geoFilters, geoFilter{field: strings.TrimPrefix(args[pos+1], "@"), area: area})
+			pos += 6
+		case "INKEYS":
+			count, err := countArg(args, pos+1)
+			if err != nil {
+				return nil, err
+			}
+			sa.inKeys = make(map[string]bool, count)
+			for _, key := range args[pos+2 : pos+2+count] {
+				sa.inKeys[key] = true
+			}
+			pos += 2 + count
+		case "INFIELDS":
+			count, err := countArg(args, pos+1)
+			if err != nil {
+				return nil, err
+			}
+			sa.inFields = args[pos+2 : pos+2+count]
+			pos += 2 + count
+		case "RETURN":
+			count, err := countArg(args, pos+1)
+			if err != nil {
+				return nil, err
+			}
+			sa.returns = parseReturns(args[pos+2 : pos+2+count])
+			pos += 2 + count
+		case "SORTBY":
+			if pos+1 >= len(args) {
+				return nil, Error("Bad arguments for SORTBY")
+			}
+			sa.sortBy = strings.TrimPrefix(args[pos+1], "@")
+			pos += 2
+			if pos < len(args) && (strings.EqualFold(args[pos], "ASC") || strings.EqualFold(args[pos], "DESC")) {
+				sa.ascending = strings.EqualFold(args[pos], "ASC")
+				pos++
+			}
+		case "LIMIT":
+			if pos+2 >= len(args) {
+				return nil, Error("Bad arguments for LIMIT")
+			}
+			offset, err1 := strconv.Atoi(args[pos+1])
+			num, err2 := strconv.Atoi(args[pos+2])
+			if err1 != nil || err2 != nil || offset < 0 || num < 0 {
+				return nil, Error("Bad arguments for LIMIT")
+			}
+			sa.offset, sa.num = offset, num
+			pos += 3
+		case "PARAMS":
+			count, err := countArg(args, pos+1)
+			if err != nil || count%2 != 0 {
+				return nil, Error("Bad arguments for PARAMS")
+			}
+			sa.params = make(map[string]string, count/2)
+			for n := pos + 2; n < pos+2+count; n += 2 {
+				sa.params[args[n]] = args[n+1]
+			}
+			pos += 2 + count
+		default:
+			return nil, Error(fmt.Sprintf("ftsearchtest: unsupported FT.SEARCH argument %s", args[pos]))
+		}
+	}
+	return sa, nil
+}
+
+// parseReturns parses RETURN attributes with optional AS names
+func parseReturns(args []string) [][2]string {
+	var returns [][2]string
+	for n := 0; n < len(args); n++ {
+		name := args[n]
+		as := name
+		if n+2 < len(args) && strings.EqualFold(args[n+1], "AS") {
+			as = args[n+2]
+			n += 2
+		}
+		returns = append(returns, [2]string{name, as})
+	}
+	return returns
+}
+
+func (sa *searchArgs) passesFilters(view *docView) bool {
+	for _, filter := range sa.filters {
+		matched := false
+		for _, value := range view.numbers[filter.field] {
+			if filter.r.contains(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, filter := range sa.geoFilters {
+		matched := false
+		for _, point := range view.points[filter.field] {
+			if filter.area.contains(point) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// sort orders hits by score, or the SORTBY attribute, falling back on
+// the key. Documents without the attribute sort last.
+func (sa *searchArgs) sort(idx *index, hits []hit) error {
+	if sa.sortBy == "" {
+		sort.SliceStable(hits, func(i, j int) bool {
+			if hits[i].score != hits[j].score {
+				return hits[i].score > hits[j].score
+			}
+			return hits[i].key < hits[j].key
+		})
+		return nil
+	}
+
+	f := idx.field(sa.sortBy)
+	if f == nil {
+		return Error(fmt.Sprintf("Property `%s` not loaded nor in schema", sa.sortBy))
+	}
+
+	sortValue := func(h hit) (string, bool) {
+		values := f.values(h.doc)
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, aok := sortValue(hits[i])
+		b, bok := sortValue(hits[j])
+		if aok != bok {
+			return aok
+		}
+		if a == b {
+			return hits[i].key < hits[j].key
+		}
+		less := a < b
+		if f.kind == kindNumeric {
+			af, _ := strconv.ParseFloat(a, 64)
+			bf, _ := strconv.ParseFloat(b, 64)
+			less = af < bf
+		}
+		if sa.ascending {
+			return less
+		}
+		return !less
+	})
+	return nil
+}
+
+// resultMap builds the RESP3 reply for the page of hits requested
+func (sa *searchArgs) resultMap(idx *index, hits []hit) map[string]interface{} {
+	results := []interface{}{}
+	for n := sa.offset; n < len(hits) && n < sa.offset+sa.num; n++ {
+		h := hits[n]
+		result := map[string]interface{}{"id": h.key, "values": []interface{}{}}
+		if sa.withScores {
+			result["score"] = h.score
+		}
+		if !sa.noContent {
+			result["extra_attributes"] = pairMap(sa.content(idx, h.doc))
+		}
+		results = append(results, result)
+	}
+
+	return map[string]interface{}{
+		"attributes":    []interface{}{},
+		"format":        "STRING",
+		"results":       results,
+		"total_results": int64(len(hits)),
+		"warning":       []interface{}{},
+	}
+}
+
+// content returns the fields of a document as returned by FT.SEARCH
+func (sa *searchArgs) content(idx *index, doc *document) []interface{} {
+	content := []interface{}{}
+
+	if len(sa.returns) == 0 {
+		if doc.json != nil {
+			encoded, _ := json.Marshal(doc.json)
+			return append(content, "$", string(encoded))
+		}
+		names := make([]string, 0, len(doc.hash))
+		for name := range doc.hash {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			content = append(content, name, doc.hash[name])
+		}
+		return content
+	}
+
+	for _, ret := range sa.returns {
+		f := idx.field(ret[0])
+		if f == nil {
+			f = &field{identifier: ret[0]}
+		}
+		if doc.json != nil {
+			matches := evalPath(doc.json, f.identifier)
+			if len(matches) > 0 {
+				content = append(content, ret[1], jsonString(matches[0]))
+			}
+		} else if value, ok := doc.hash[f.identifier]; ok {
+			content = append(content, ret[1], value)
+		}
+	}
+	return content
+}
+
+// view extracts the indexed values of a document
+func (idx *index) view(key string, doc *document) *docView {
+	view := &docView{
+		key:     key,
+		text:    make(map[string][]string),
+		weights: make(map[string]float64),
+		tags:    make(map[string][]string),
+		numbers: make(map[string][]float64),
+		points:  make(map[string][]geoPoint),
+		shapes:  make(map[string][]shape),
+	}
+
+	for _, f := range idx.fields {
+		if f.noIndex {
+			continue
+		}
+		for _, value := range f.values(doc) {
+			switch f.kind {
+			case kindText:
+				view.text[f.name] = append(view.text[f.name], tokenize(value)...)
+				view.weights[f.name] = f.weight
+			case kindTag:
+				for _, tag := range f.split(value, doc.json != nil) {
+					view.tags[f.name] = append(view.tags[f.name], tag)
+				}
+			case kindNumeric:
+				if number, err := strconv.ParseFloat(value, 64); err == nil {
+					view.numbers[f.name] = append(view.numbers[f.name], number)
+				}
+			case kindGeo:
+				if point, ok := parseGeoPoint(value); ok {
+					view.points[f.name] = append(view.points[f.name], point)
+				}
+			case kindShape:
+				if s, ok := parseWKT(value); ok {
+					view.shapes[f.name] = append(view.shapes[f.name], s)
+				}
+			}
+		}
+	}
+	return view
+}
+
+// split splits a TAG value on the separator, trimming and, unless the
+// field is case sensitive, lower casing each tag. JSON values are not
+// split as arrays hold one tag per item.
+func (f *field) split(value string, isJSON bool) []string {
+	parts := []string{value}
+	if !isJSON && f.separator != "" {
+		parts = strings.Split(value, f.separator)
+	}
+
+	var tags []string
+	for _, part := range parts {
+		tag := strings.TrimSpace(part)
+		if tag == "" {
+			continue
+		}
+		if !f.caseSensitive {
+			tag = strings.ToLower(tag)
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -0,0 +1,294 @@
+package ftsearchtest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server serves an Engine over TCP using the Redis protocol so that real
+// clients can be pointed at it. Connections start with RESP2 and switch
+// to RESP3 after HELLO 3, after which FT.SEARCH and FT.INFO reply with
+// maps as RediSearch does.
+//
+// Besides the commands of the engine, the server answers PING, ECHO,
+// HELLO, AUTH, SELECT, CLIENT, READONLY and QUIT so that connection set
+// up by the usual clients succeeds.
+type Server struct {
+	engine   *Engine
+	listener net.Listener
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	closed   bool
+}
+
+// NewServer starts a server for the engine on a free local port
+func NewServer(e *Engine) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		engine:   e,
+		listener: listener,
+		conns:    make(map[net.Conn]bool),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server listens on, as host:port
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server, closing any open connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	err := s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+// session is the state of a single connection
+type session struct {
+	reader   *bufio.Reader
+	writer   *bufio.Writer
+	protocol int
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	sess := &session{reader: bufio.NewReader(conn), writer: bufio.NewWriter(conn), protocol: 2}
+	for {
+		args, err := sess.readCommand()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				sess.writeReply(Error("ERR Protocol error: " + err.Error()))
+				sess.writer.Flush()
+			}
+			return
+		}
+
+		quit := len(args) > 0 && strings.EqualFold(args[0], "QUIT")
+		sess.writeReply(s.dispatch(sess, args))
+
+		// flush once all pipelined commands have been answered
+		if sess.reader.Buffered() == 0 || quit {
+			if sess.writer.Flush() != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+// dispatch runs a command, returning its reply or error
+func (s *Server) dispatch(sess *session, args []string) interface{} {
+	if len(args) == 0 {
+		return Error("ERR empty command")
+	}
+
+	switch name := strings.ToUpper(args[0]); name {
+	case "PING":
+		if len(args) > 1 {
+			return args[1]
+		}
+		return status("PONG")
+	case "ECHO":
+		if len(args) != 2 {
+			return wrongArgs(name)
+		}
+		return args[1]
+	case "HELLO":
+		return sess.hello(args[1:])
+	case "AUTH", "SELECT", "CLIENT", "READONLY", "QUIT":
+		return status("OK")
+	default:
+		cmd := make([]interface{}, len(args))
+		for n, arg := range args {
+			cmd[n] = arg
+		}
+		val, err := s.engine.do(sess.protocol, cmd)
+		if err != nil {
+			return err
+		}
+		if val == "OK" {
+			return status("OK")
+		}
+		return val
+	}
+}
+
+// hello handles HELLO [protover [AUTH user pass] [SETNAME name]]
+func (sess *session) hello(args []string) interface{} {
+	if len(args) > 0 {
+		protocol, err := strconv.Atoi(args[0])
+		if err != nil || protocol < 2 || protocol > 3 {
+			return Error("NOPROTO unsupported protocol version")
+		}
+		sess.protocol = protocol
+	}
+
+	return map[string]interface{}{
+		"server":  "redis",
+		"version": "7.2.0",
+		"proto":   int64(sess.protocol),
+		"id":      int64(1),
+		"mode":    "standalone",
+		"role":    "master",
+		"modules": []interface{}{},
+	}
+}
+
+// status is a simple string reply
+type status string
+
+// Limits on commands read from clients, matching Redis' defaults for
+// proto-max-bulk-len and the longest multibulk it accepts
+const (
+	maxBulkLen      = 512 * 1024 * 1024
+	maxMultiBulkLen = 1024 * 1024
+)
+
+// readCommand reads a command sent as an array of bulk strings
+func (sess *session) readCommand() ([]string, error) {
+	line, err := sess.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("expected '*', got %q", line)
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 0 || count > maxMultiBulkLen {
+		return nil, fmt.Errorf("invalid multibulk length %q", line)
+	}
+
+	args := make([]string, count)
+	for n := range args {
+		line, err := sess.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("expected '$', got %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, fmt.Errorf("invalid bulk length %q", line)
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(sess.reader, data); err != nil {
+			return nil, err
+		}
+		args[n] = string(data[:size])
+	}
+	return args, nil
+}
+
+func (sess *session) readLine() (string, error) {
+	line, err := sess.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// writeReply encodes a reply for the protocol of the session. RESP2 has
+// no map, double or null types so maps are flattened into arrays, doubles
+// sent as bulk strings and nil as a null bulk string.
+func (sess *session) writeReply(reply interface{}) {
+	w := sess.writer
+	switch r := reply.(type) {
+	case nil:
+		if sess.protocol == 3 {
+			w.WriteString("_\r\n")
+		} else {
+			w.WriteString("$-1\r\n")
+		}
+	case status:
+		fmt.Fprintf(w, "+%s\r\n", r)
+	case error:
+		fmt.Fprintf(w, "-%s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(r.Error()))
+	case string:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(r), r)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", r)
+	case float64:
+		text := strconv.FormatFloat(r, 'g', -1, 64)
+		if sess.protocol == 3 {
+			fmt.Fprintf(w, ",%s\r\n", text)
+		} else {
+			fmt.Fprintf(w, "$%d\r\n%s\r\n", len(text), text)
+		}
+	case []interface{}:
+		fmt.Fprintf(w, "*%d\r\n", len(r))
+		for _, item := range r {
+			sess.writeReply(item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(r))
+		for key := range r {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		if sess.protocol == 3 {
+			fmt.Fprintf(w, "%%%d\r\n", len(r))
+		} else {
+			fmt.Fprintf(w, "*%d\r\n", len(r)*2)
+		}
+		for _, key := range keys {
+			sess.writeReply(key)
+			sess.writeReply(r[key])
+		}
+	default:
+		sess.writeReply(Error(fmt.Sprintf("ERR ftsearchtest: cannot encode reply of type %T", reply)))
+	}
+}
@@ -0,0 +1,138 @@
+package ftsearchtest
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nic-gibson/go-redis-search/adapters/goredisv8"
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerWithGoRedis(t *testing.T) {
+	srv, err := NewServer(New())
+	require.NoError(t, err)
+	defer srv.Close()
+
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer rdb.Close()
+	client := goredisv8.NewClient(rdb)
+	ctx := context.Background()
+
+	_, err = client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex("books").
+		WithSchema(ftsearch.NewSchema().WithIdentifier("title").AttributeType("TEXT")).
+		WithSchema(ftsearch.NewSchema().WithIdentifier("year").AttributeType("NUMERIC")))
+	require.NoError(t, err)
+
+	require.NoError(t, rdb.HSet(ctx, "book:1", "title", "Learning Go", "year", "2021").Err())
+	require.NoError(t, rdb.HSet(ctx, "book:2", "title", "The Hobbit", "year", "1937").Err())
+	require.NoError(t, rdb.Do(ctx, "JSON.SET", "book:3", "$", `{"title":"Go"}`).Err())
+
+	results, err := client.Search(ctx, ftsearch.NewQuery().WithIndex("books").WithQueryString("go"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), results.Count)
+	require.Equal(t, "2021", results.Data["book:1"].Value["year"])
+
+	deleted, err := rdb.Del(ctx, "book:1", "book:9").Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = client.Search(ctx, ftsearch.NewQuery().WithIndex("missing").WithQueryString("*"))
+	require.EqualError(t, err, "missing: no such index")
+}
+
+func TestServerRESP3(t *testing.T) {
+	srv, err := NewServer(New())
+	require.NoError(t, err)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	_, err = conn.Write([]byte("*2\r\n$5\r\nHELLO\r\n$1\r\n3\r\n*1\r\n$8\r\nFT._LIST\r\n*2\r\n$4\r\nPING\r\n$2\r\nhi\r\n"))
+	require.NoError(t, err)
+
+	hello, err := readValue(reader)
+	require.NoError(t, err)
+	require.Equal(t, "%7", hello[0])
+	require.Contains(t, hello, ":3")
+
+	list, err := readValue(reader)
+	require.NoError(t, err)
+	require.Equal(t, []string{"*0"}, list)
+
+	pong, err := readValue(reader)
+	require.NoError(t, err)
+	require.Equal(t, []string{"$2", "hi"}, pong)
+}
+
+func TestServerRejectsBadLengths(t *testing.T) {
+	srv, err := NewServer(New())
+	require.NoError(t, err)
+	defer srv.Close()
+
+	for _, request := range []string{
+		"*1\r\n$-5\r\n",
+		"*1\r\n$536870913\r\n",
+		"*1048577\r\n",
+		"*-1\r\n",
+	} {
+		conn, err := net.Dial("tcp", srv.Addr())
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte(request))
+		require.NoError(t, err)
+		reply, err := readValue(bufio.NewReader(conn))
+		require.NoError(t, err)
+		require.Len(t, reply, 1)
+		require.True(t, strings.HasPrefix(reply[0], "-ERR Protocol error: invalid"), request)
+		conn.Close()
+	}
+}
+
+// readValue reads the lines making up a single RESP value
+func readValue(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	lines := []string{line}
+
+	children := 0
+	switch line[0] {
+	case '$':
+		children = 1
+	case '*':
+		children, _ = strconv.Atoi(line[1:])
+	case '%':
+		children, _ = strconv.Atoi(line[1:])
+		children *= 2
+	}
+	if line == "$-1" {
+		children = 0
+	}
+
+	for n := 0; n < children; n++ {
+		if line[0] == '$' {
+			text, err := reader.ReadString('\n')
+			if err != nil {
+				return nil, err
+			}
+			return append(lines, strings.TrimSuffix(text, "\r\n")), nil
+		}
+		child, err := readValue(reader)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, child...)
+	}
+	return lines, nil
+}
@@ -0,0 +1,105 @@
+package ftsearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/******************************************************************************
+* Geo search                                                                  *
+******************************************************************************/
+
+// GeoUnit is a unit of distance accepted by GEOFILTER and geo queries
+type GeoUnit string
+
+const (
+	Meters     GeoUnit = "m"
+	Kilometers GeoUnit = "km"
+	Miles      GeoUnit = "mi"
+	Feet       GeoUnit = "ft"
+)
+
+// valid checks the unit is one RediSearch accepts
+func (u GeoUnit) valid() bool {
+	switch u {
+	case Meters, Kilometers, Miles, Feet:
+		return true
+	default:
+		return false
+	}
+}
+
+// queryGeoFilter restricts results to those within radius of a point
+type queryGeoFilter struct {
+	Attribute string
+	Lon       float64
+	Lat       float64
+	Radius    float64
+	Unit      GeoUnit
+}
+
+type queryGeoFilterList []*queryGeoFilter
+
+// NewQueryGeoFilter returns a geo filter for the attribute given
+func NewQueryGeoFilter(attribute string, lon float64, lat float64, radius float64, unit GeoUnit) *queryGeoFilter {
+	return &queryGeoFilter{Attribute: attribute, Lon: lon, Lat: lat, Radius: radius, Unit: unit}
+}
+
+// validate checks the point is a valid position and the radius and unit
+// are usable
+func (gf *queryGeoFilter) validate() error {
+	if err := validateGeoPoint(gf.Lon, gf.Lat); err != nil {
+		return fmt.Errorf("ftsearch: GEOFILTER %s: %w", gf.Attribute, err)
+	}
+	if gf.Radius < 0 {
+		return fmt.Errorf("ftsearch: GEOFILTER %s: negative radius %g", gf.Attribute, gf.Radius)
+	}
+	if !gf.Unit.valid() {
+		return fmt.Errorf("ftsearch: GEOFILTER %s: unknown unit %q, expected m, km, mi or ft", gf.Attribute, gf.Unit)
+	}
+	return nil
+}
+
+// serialize converts a geo filter list to arguments for execution
+func (q queryGeoFilterList) serialize() []interface{} {
+	var args []interface{}
+	for _, gf := range q {
+		args = append(args, "GEOFILTER", gf.Attribute, formatGeo(gf.Lon), formatGeo(gf.Lat), formatGeo(gf.Radius), string(gf.Unit))
+	}
+	return args
+}
+
+// validateGeoPoint checks a position is within the range Redis can index
+func validateGeoPoint(lon float64, lat float64) error {
+	if lon < -180 || lon > 180 {
+		return fmt.Errorf("longitude %g out of range", lon)
+	}
+	if lat < -85.05112878 || lat > 85.05112878 {
+		return fmt.Errorf("latitude %g out of range", lat)
+	}
+	return nil
+}
+
+// formatGeo formats a coordinate or distance with as many digits as needed
+func formatGeo(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+// GeoQuery returns the query string clause matching documents whose
+// attribute is within radius of a point, @attribute:[lon lat radius unit].
+// Invalid units are reported by the server when the query is run.
+func GeoQuery(attribute string, lon float64, lat float64, radius float64, unit GeoUnit) string {
+	return fmt.Sprintf("@%s:[%s %s %s %s]", strings.TrimPrefix(attribute, "@"),
+		formatGeo(lon), formatGeo(lat), formatGeo(radius), unit)
+}
+
+// GeoDistance returns an APPLY expression computing the distance in meters
+// between the attribute, which must be loaded, and a point. For example:
+//
+//	NewAggregate().WithLoad([]string{"@location"}).
+//		AddApply(GeoDistance("location", lon, lat), "distance").
+//		AddSortBy([]string{"@distance", "ASC"}, 0)
+func GeoDistance(attribute string, lon float64, lat float64) string {
+	return fmt.Sprintf(`geodistance(@%s, "%s,%s")`, strings.TrimPrefix(attribute, "@"), formatGeo(lon), formatGeo(lat))
+}
@@ -0,0 +1,32 @@
+package ftsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeoFilter(t *testing.T) {
+	qry := NewQuery().WithIndex("stores").WithQueryString("*").
+		AddGeoFilter("location", -0.1278, 51.5074, 2.5, Kilometers)
+	require.Equal(t, "[FT.SEARCH stores * GEOFILTER location -0.1278 51.5074 2.5 km]", qry.String())
+
+	client, fake := newFakeClient(map[string]interface{}{"FT.SEARCH": []interface{}{int64(0)}})
+	_, err := client.Search(context.Background(), NewQuery().WithIndex("stores").
+		AddGeoFilter("location", -0.1278, 51.5074, 2.5, "yards"))
+	require.EqualError(t, err, `ftsearch: GEOFILTER location: unknown unit "yards", expected m, km, mi or ft`)
+
+	_, err = client.Search(context.Background(), NewQuery().WithIndex("stores").
+		AddGeoFilter("location", 51.5074, -190, 2.5, Miles))
+	require.EqualError(t, err, "ftsearch: GEOFILTER location: latitude -190 out of range")
+	require.Empty(t, fake.sent)
+}
+
+func TestGeoHelpers(t *testing.T) {
+	require.Equal(t, "@location:[-0.1278 51.5074 500 m]", GeoQuery("location", -0.1278, 51.5074, 500, Meters))
+	require.Equal(t, `geodistance(@location, "-0.1278,51.5074")`, GeoDistance("@location", -0.1278, 51.5074))
+
+	require.Equal(t, "[FT.CREATE stores ON HASH SCHEMA location GEO]",
+		NewCreate().WithIndex("stores").WithSchema(NewSchema().WithIdentifier("location").AsGeo()).String())
+}
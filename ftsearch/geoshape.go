@@ -0,0 +1,142 @@
+package ftsearch
+
+import (
+	"fmt"
+	"strings"
+)
+
+/******************************************************************************
+* GEOSHAPE search                                                             *
+******************************************************************************/
+
+// CoordSystem is the coordinate system of a GEOSHAPE field
+type CoordSystem string
+
+const (
+	// Spherical coordinates are longitude and latitude
+	Spherical CoordSystem = "SPHERICAL"
+	// Flat coordinates are on a cartesian plane
+	Flat CoordSystem = "FLAT"
+)
+
+// SpatialPredicate is the relation a GEOSHAPE query tests for
+type SpatialPredicate string
+
+const (
+	Within     SpatialPredicate = "WITHIN"
+	Contains   SpatialPredicate = "CONTAINS"
+	Intersects SpatialPredicate = "INTERSECTS"
+	Disjoint   SpatialPredicate = "DISJOINT"
+)
+
+// geoShapeDialect is the lowest dialect supporting GEOSHAPE queries
+const geoShapeDialect = 3
+
+// Shape is a geometry which can be written as WKT, the format GEOSHAPE
+// fields are stored and queried in
+type Shape interface {
+	WKT() string
+}
+
+// Point is a position; X is the longitude for spherical coordinates and
+// Y the latitude
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Polygon is a polygon without holes given by its vertices. The ring is
+// closed when written if the last point is not the first.
+type Polygon []Point
+
+// WKT returns the point as POINT (x y)
+func (p Point) WKT() string {
+	return fmt.Sprintf("POINT (%s)", p.coordinates())
+}
+
+func (p Point) coordinates() string {
+	return formatGeo(p.X) + " " + formatGeo(p.Y)
+}
+
+// WKT returns the polygon as POLYGON ((x y, ...))
+func (p Polygon) WKT() string {
+	points := make([]string, 0, len(p)+1)
+	for _, point := range p {
+		points = append(points, point.coordinates())
+	}
+	if len(p) > 0 && p[0] != p[len(p)-1] {
+		points = append(points, p[0].coordinates())
+	}
+	return fmt.Sprintf("POLYGON ((%s))", strings.Join(points, ", "))
+}
+
+// validate checks the polygon has enough distinct vertices to have an area
+func (p Polygon) validate() error {
+	vertices := len(p)
+	if vertices > 0 && p[0] == p[vertices-1] {
+		vertices--
+	}
+	if vertices < 3 {
+		return fmt.Errorf("ftsearch: polygon needs at least 3 vertices, got %d", vertices)
+	}
+	return nil
+}
+
+// valid checks the predicate is one RediSearch accepts
+func (sp SpatialPredicate) valid() bool {
+	switch sp {
+	case Within, Contains, Intersects, Disjoint:
+		return true
+	default:
+		return false
+	}
+}
+
+// GeoShapeQuery returns the query string clause testing a GEOSHAPE
+// attribute against the shape in the parameter given, for example
+// @zone:[CONTAINS $point]. The query must set the parameter and use
+// dialect 3 or later.
+func GeoShapeQuery(attribute string, predicate SpatialPredicate, param string) string {
+	return fmt.Sprintf("@%s:[%s $%s]", strings.TrimPrefix(attribute, "@"), predicate, strings.TrimPrefix(param, "$"))
+}
+
+// geoShapeParamPrefix starts the names of parameters added by AddGeoShape,
+// which should not be used for other parameters
+const geoShapeParamPrefix = "__geoshape"
+
+// AddGeoShape adds a clause testing a GEOSHAPE attribute against the shape
+// to the query string, passing the shape as WKT through a parameter named
+// __geoshape followed by a number not already in use. The clause and its
+// parameter are written when the query is serialized, so the query string
+// and other parameters may be set before or after. The dialect is raised
+// to 3 if needed. The updated query is returned.
+func (q *query) AddGeoShape(attribute string, predicate SpatialPredicate, shape Shape) *query {
+	q.Clauses = append(q.Clauses, &queryGeoShape{Attribute: attribute, Predicate: predicate, Shape: shape})
+	return q
+}
+
+// queryGeoShape is a shape added with AddGeoShape
+type queryGeoShape struct {
+	Attribute string
+	Predicate SpatialPredicate
+	Shape     Shape
+}
+
+func (gs *queryGeoShape) build(params queryParamList) (string, queryParamList) {
+	param := params.unusedName(geoShapeParamPrefix)
+	return GeoShapeQuery(gs.Attribute, gs.Predicate, param), params.set(param, gs.Shape.WKT())
+}
+
+func (gs *queryGeoShape) dialect() int32 {
+	return geoShapeDialect
+}
+
+func (gs *queryGeoShape) validate() error {
+	if !gs.Predicate.valid() {
+		return fmt.Errorf("ftsearch: unknown spatial predicate %q", gs.Predicate)
+	}
+	if polygon, ok := gs.Shape.(Polygon); ok {
+		return polygon.validate()
+	}
+	return nil
+}
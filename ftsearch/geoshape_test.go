@@ -0,0 +1,46 @@
+package ftsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeoShapeWKT(t *testing.T) {
+	require.Equal(t, "POINT (-0.1278 51.5074)", Point{X: -0.1278, Y: 51.5074}.WKT())
+	require.Equal(t, "POLYGON ((0 0, 2 0, 2 2, 0 0))", Polygon{{0, 0}, {2, 0}, {2, 2}}.WKT())
+	require.Equal(t, "POLYGON ((0 0, 2 0, 2 2, 0 0))", Polygon{{0, 0}, {2, 0}, {2, 2}, {0, 0}}.WKT())
+}
+
+func TestGeoShapeQuery(t *testing.T) {
+	qry := NewQuery().WithIndex("zones").WithQueryString("@open:{yes}").
+		AddGeoShape("area", Contains, Point{X: 1, Y: 1})
+	require.Equal(t, "[FT.SEARCH zones (@open:{yes}) @area:[CONTAINS $__geoshape0] PARAMS 2 __geoshape0 POINT (1 1) DIALECT 3]", qry.String())
+
+	qry = NewQuery().WithIndex("zones").WithQueryString("@code:{$shape1}").
+		AddParam("shape1", "x").AddParam("__geoshape1", "y").
+		AddGeoShape("area", Contains, Point{X: 1, Y: 1}).
+		AddGeoShape("site", Within, Point{X: 2, Y: 2})
+	require.Equal(t, "[FT.SEARCH zones ((@code:{$shape1}) @area:[CONTAINS $__geoshape0]) @site:[WITHIN $__geoshape2] "+
+		"PARAMS 8 shape1 x __geoshape1 y __geoshape0 POINT (1 1) __geoshape2 POINT (2 2) DIALECT 3]", qry.String())
+
+	qry = NewQuery().WithIndex("zones").AddGeoShape("area", Contains, Point{X: 1, Y: 2}).
+		WithQueryString("@open:{yes}").AddParam("__geoshape0", "x")
+	require.Equal(t, "[FT.SEARCH zones (@open:{yes}) @area:[CONTAINS $__geoshape1] PARAMS 4 __geoshape0 x __geoshape1 POINT (1 2) DIALECT 3]", qry.String())
+	require.Equal(t, qry.String(), qry.String())
+	require.Equal(t, "@area:[WITHIN $zone]", GeoShapeQuery("@area", Within, "$zone"))
+
+	require.Equal(t, "[FT.CREATE zones ON HASH SCHEMA area GEOSHAPE FLAT]",
+		NewCreate().WithIndex("zones").WithSchema(NewSchema().WithIdentifier("area").AsGeoShape(Flat)).String())
+
+	client, fake := newFakeClient(map[string]interface{}{"FT.SEARCH": []interface{}{int64(0)}})
+	_, err := client.Search(context.Background(), NewQuery().WithIndex("zones").
+		AddGeoShape("area", Within, Polygon{{0, 0}, {1, 1}}))
+	require.EqualError(t, err, "ftsearch: polygon needs at least 3 vertices, got 2")
+
+	_, err = client.Search(context.Background(), NewQuery().WithIndex("zones").
+		AddGeoShape("area", "TOUCHES", Point{}))
+	require.EqualError(t, err, `ftsearch: unknown spatial predicate "TOUCHES"`)
+	require.Empty(t, fake.sent)
+}
@@ -0,0 +1,454 @@
+// profile provides an interface to RedisSearch's profiling functionality.
+package ftsearch
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Profilable is implemented by the queries and aggregates which can
+// be passed to Profile.
+type Profilable interface {
+	commandArgs(ctx context.Context, c *Client) ([]interface{}, error)
+	profileType() string
+	parseProfiled(reply interface{}, results *ProfileResults) error
+}
+
+// ProfileResults holds the results of the profiled command alongside the
+// profile itself. Only one of Search and Aggregate is set, depending on
+// what was profiled.
+type ProfileResults struct {
+	Search    *QueryResults
+	Aggregate *AggregateResults
+	Profile   *QueryProfile
+}
+
+// QueryProfile is the profile returned by FT.PROFILE. Times are in milliseconds.
+type QueryProfile struct {
+	TotalTime            float64
+	ParsingTime          float64
+	PipelineCreationTime float64
+	Warning              string
+	Iterators            *IteratorProfile
+	ResultProcessors     []*ResultProcessorProfile
+}
+
+// IteratorProfile is a single node in the iterator tree. Term is set for
+// term iterators and QueryType for intersections, unions and the like.
+type IteratorProfile struct {
+	Type      string
+	QueryType string
+	Term      string
+	Time      float64
+	Counter   int64
+	Size      int64
+	Children  []*IteratorProfile
+}
+
+// ResultProcessorProfile is the profile of a single result processor
+type ResultProcessorProfile struct {
+	Type    string
+	Time    float64
+	Counter int64
+}
+
+const (
+	profileShards           = "Shards"
+	profileTotalTime        = "Total profile time"
+	profileParsingTime      = "Parsing time"
+	profilePipelineTime     = "Pipeline creation time"
+	profileWarning          = "Warning"
+	profileIterators        = "Iterators profile"
+	profileResultProcessors = "Result processors profile"
+	profileChildIterators   = "Child iterators"
+)
+
+// Profile runs the query or aggregate given under FT.PROFILE, returning both
+// the normal results and the profile. If limited is set the profile does
+// not include the details of reader iterators.
+func (c *Client) Profile(ctx context.Context, qry Profilable, limited bool) (*ProfileResults, error) {
+	command, err := qry.commandArgs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	serialized := profileArgs(qry, command, limited)
+	if reply, err := c.do(ctx, serialized); err != nil {
+		return nil, err
+	} else if m, ok := replyMap(reply); ok {
+		return parseProfileMap(qry, m)
+	} else if rawResults, err := replySlice(reply); err != nil {
+		return nil, err
+	} else {
+		results := &ProfileResults{}
+		if len(rawResults) < 2 {
+			return nil, newReplyError(elementPath("reply", len(rawResults)), "an array", nil)
+		}
+		if err := qry.parseProfiled(rawResults[0], results); err != nil {
+			return nil, err
+		}
+		if profile, err := decodeArray(elementPath("reply", 1), rawResults[1]); err != nil {
+			return nil, err
+		} else if results.Profile, err = parseProfile(elementPath("reply", 1), profile); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+}
+
+// parseProfileMap converts a RESP3 FT.PROFILE reply. The results and the
+// profile are sent under "Results" and "Profile", or in lower case by
+// older servers. The profile is flattened into the RESP2 layout so that
+// parseProfile can read it.
+func parseProfileMap(qry Profilable, reply map[string]interface{}) (*ProfileResults, error) {
+	results := &ProfileResults{}
+	var parsed bool
+	for name, value := range reply {
+		path := memberPath("reply", name)
+		switch strings.ToLower(name) {
+		case "results":
+			parsed = true
+			if err := qry.parseProfiled(value, results); err != nil {
+				return nil, err
+			}
+		case "profile":
+			if profile, err := decodeArray(path, flattenProfile(value)); err != nil {
+				return nil, err
+			} else if results.Profile, err = parseProfile(path, profile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !parsed {
+		return nil, newReplyError(memberPath("reply", "Results"), "an array", nil)
+	} else if results.Profile == nil {
+		return nil, newReplyError(memberPath("reply", "Profile"), "an array", nil)
+	}
+	return results, nil
+}
+
+// flattenProfile converts the maps in a RESP3 profile into flat lists of
+// names and values. Names are sorted, except that child iterators are
+// moved to the end where parseIteratorProfile expects them.
+func flattenProfile(val interface{}) interface{} {
+	if m, ok := replyMap(val); ok {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if (names[i] == profileChildIterators) != (names[j] == profileChildIterators) {
+				return names[j] == profileChildIterators
+			}
+			return names[i] < names[j]
+		})
+
+		flat := make([]interface{}, 0, 2*len(names))
+		for _, name := range names {
+			flat = append(flat, name, flattenProfile(m[name]))
+		}
+		return flat
+	}
+
+	if list, ok := val.([]interface{}); ok {
+		flat := make([]interface{}, len(list))
+		for n, item := range list {
+			flat[n] = flattenProfile(item)
+		}
+		return flat
+	}
+	return val
+}
+
+// profileArgs rewrites the serialized command into an FT.PROFILE command
+func profileArgs(qry Profilable, serialized []interface{}, limited bool) []interface{} {
+	args := []interface{}{"FT.PROFILE", serialized[1], qry.profileType()}
+	if limited {
+		args = append(args, "LIMITED")
+	}
+	args = append(args, "QUERY")
+	return append(args, serialized[2:]...)
+}
+
+// commandArgs validates the query and serializes it as Search does
+func (q *query) commandArgs(ctx context.Context, c *Client) ([]interface{}, error) {
+	return c.searchArgs(ctx, q)
+}
+
+func (q *query) profileType() string {
+	return "SEARCH"
+}
+
+func (q *query) parseProfiled(reply interface{}, results *ProfileResults) (err error) {
+	results.Search, err = q.parseReply(reply)
+	return err
+}
+
+// commandArgs validates the aggregate and serializes it as Aggregate does
+func (a *aggregate) commandArgs(ctx context.Context, c *Client) ([]interface{}, error) {
+	return c.aggregateArgs(ctx, a)
+}
+
+func (a *aggregate) profileType() string {
+	return "AGGREGATE"
+}
+
+func (a *aggregate) parseProfiled(reply interface{}, results *ProfileResults) (err error) {
+	results.Aggregate, err = a.parseReply(reply)
+	return err
+}
+
+// parseProfile converts the profile section of an FT.PROFILE reply. Older
+// servers return a list of [name, value...] entries, newer ones wrap flat
+// name/value lists in a Shards section - only the first shard is used.
+func parseProfile(path string, raw []interface{}) (*QueryProfile, error) {
+	entries, err := profileEntries(path, raw)
+	if err != nil {
+		return nil, err
+	}
+	if shards, ok := entries[profileShards]; ok && len(shards) > 0 {
+		path = elementPath(memberPath(path, profileShards), 0)
+		shard, err := decodeArray(path, shards[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(shard) > 0 {
+			if first, ok := shard[0].([]interface{}); ok {
+				path, shard = elementPath(path, 0), first
+			}
+		}
+		if entries, err = profileEntries(path, shard); err != nil {
+			return nil, err
+		}
+	}
+
+	profile := &QueryProfile{}
+	if profile.TotalTime, err = profileFloat(memberPath(path, profileTotalTime), firstValue(entries[profileTotalTime])); err != nil {
+		return nil, err
+	}
+	if profile.ParsingTime, err = profileFloat(memberPath(path, profileParsingTime), firstValue(entries[profileParsingTime])); err != nil {
+		return nil, err
+	}
+	if profile.PipelineCreationTime, err = profileFloat(memberPath(path, profilePipelineTime), firstValue(entries[profilePipelineTime])); err != nil {
+		return nil, err
+	}
+	if profile.Warning, err = profileString(memberPath(path, profileWarning), firstValue(entries[profileWarning])); err != nil {
+		return nil, err
+	}
+
+	iteratorsPath := memberPath(path, profileIterators)
+	if nodes, err := profileNodes(iteratorsPath, entries[profileIterators]); err != nil {
+		return nil, err
+	} else if len(nodes) > 0 {
+		if profile.Iterators, err = parseIteratorProfile(nodes[0].path, nodes[0].values); err != nil {
+			return nil, err
+		}
+	}
+
+	processorsPath := memberPath(path, profileResultProcessors)
+	if nodes, err := profileNodes(processorsPath, entries[profileResultProcessors]); err != nil {
+		return nil, err
+	} else {
+		for _, node := range nodes {
+			if processor, err := parseResultProcessorProfile(node.path, node.values); err != nil {
+				return nil, err
+			} else {
+				profile.ResultProcessors = append(profile.ResultProcessors, processor)
+			}
+		}
+	}
+
+	return profile, nil
+}
+
+// profileEntries indexes a profile section by name. Both nested
+// [name, value...] entries and flat name, value lists are accepted.
+func profileEntries(path string, raw []interface{}) (map[string][]interface{}, error) {
+	entries := make(map[string][]interface{})
+
+	if len(raw) > 0 {
+		if _, flat := raw[0].(string); flat {
+			if len(raw)%2 != 0 {
+				return nil, newReplyError(elementPath(path, len(raw)), "a value", nil)
+			}
+			for i := 0; i < len(raw); i += 2 {
+				if name, err := decodeString(elementPath(path, i), raw[i]); err != nil {
+					return nil, err
+				} else {
+					entries[name] = []interface{}{raw[i+1]}
+				}
+			}
+			return entries, nil
+		}
+	}
+
+	for n, item := range raw {
+		entry, err := decodeArray(elementPath(path, n), item)
+		if err != nil {
+			return nil, err
+		}
+		if len(entry) == 0 {
+			return nil, newReplyError(elementPath(elementPath(path, n), 0), "a string", nil)
+		}
+		if name, err := decodeString(elementPath(elementPath(path, n), 0), entry[0]); err != nil {
+			return nil, err
+		} else {
+			entries[name] = entry[1:]
+		}
+	}
+	return entries, nil
+}
+
+// profileNode is a node description together with its path in the reply
+type profileNode struct {
+	path   string
+	values []interface{}
+}
+
+// profileNodes returns the node descriptions held in values, which may be
+// the nodes themselves or a single list of nodes. Empty lists are skipped.
+func profileNodes(path string, values []interface{}) ([]profileNode, error) {
+	var nodes []profileNode
+	for n, value := range values {
+		nodePath := elementPath(path, n)
+		node, err := decodeArray(nodePath, value)
+		if err != nil {
+			return nil, err
+		}
+		if len(node) == 0 {
+			continue
+		}
+		if _, isNode := node[0].(string); isNode {
+			nodes = append(nodes, profileNode{path: nodePath, values: node})
+		} else if children, err := profileNodes(nodePath, node); err != nil {
+			return nil, err
+		} else {
+			nodes = append(nodes, children...)
+		}
+	}
+	return nodes, nil
+}
+
+func parseIteratorProfile(path string, node []interface{}) (*IteratorProfile, error) {
+	iterator := &IteratorProfile{}
+	for i := 0; i < len(node); i += 2 {
+		name, err := decodeString(elementPath(path, i), node[i])
+		if err != nil {
+			return nil, err
+		}
+		if name == profileChildIterators {
+			children, err := profileNodes(memberPath(path, profileChildIterators), node[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				if childProfile, err := parseIteratorProfile(child.path, child.values); err != nil {
+					return nil, err
+				} else {
+					iterator.Children = append(iterator.Children, childProfile)
+				}
+			}
+			return iterator, nil
+		}
+		if i+1 == len(node) {
+			return nil, newReplyError(memberPath(path, name), "a value", nil)
+		}
+
+		valuePath := memberPath(path, name)
+		switch name {
+		case "Type":
+			iterator.Type, err = profileString(valuePath, node[i+1])
+		case "Query type":
+			iterator.QueryType, err = profileString(valuePath, node[i+1])
+		case "Term":
+			iterator.Term, err = profileString(valuePath, node[i+1])
+		case "Time":
+			iterator.Time, err = profileFloat(valuePath, node[i+1])
+		case "Counter":
+			iterator.Counter, err = profileInt(valuePath, node[i+1])
+		case "Size":
+			iterator.Size, err = profileInt(valuePath, node[i+1])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return iterator, nil
+}
+
+func parseResultProcessorProfile(path string, node []interface{}) (*ResultProcessorProfile, error) {
+	if len(node)%2 != 0 {
+		return nil, newReplyError(elementPath(path, len(node)), "a value", nil)
+	}
+	processor := &ResultProcessorProfile{}
+	for i := 0; i < len(node); i += 2 {
+		name, err := decodeString(elementPath(path, i), node[i])
+		if err != nil {
+			return nil, err
+		}
+		valuePath := memberPath(path, name)
+		switch name {
+		case "Type":
+			processor.Type, err = profileString(valuePath, node[i+1])
+		case "Time":
+			processor.Time, err = profileFloat(valuePath, node[i+1])
+		case "Counter":
+			processor.Counter, err = profileInt(valuePath, node[i+1])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return processor, nil
+}
+
+func firstValue(values []interface{}) interface{} {
+	if len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// profileString formats a profile value as a string. Missing values are
+// left empty.
+func profileString(path string, val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	default:
+		return "", newReplyError(path, "a string", val)
+	}
+}
+
+// profileFloat reads a profile time. Missing values are left at zero.
+func profileFloat(path string, val interface{}) (float64, error) {
+	if val == nil {
+		return 0, nil
+	}
+	return decodeFloat(path, val)
+}
+
+// profileInt reads a profile counter, which may be sent as a string or a
+// double. Missing values are left at zero.
+func profileInt(path string, val interface{}) (int64, error) {
+	switch v := val.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	}
+	return 0, newReplyError(path, "an integer", val)
+}
@@ -0,0 +1,117 @@
+package ftsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileArgs(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{
+		"FT.PROFILE": []interface{}{[]interface{}{int64(0)}, []interface{}{}},
+	})
+	client.WithDefaultTimeout(time.Second)
+	ctx := context.Background()
+
+	qry := NewQuery().WithIndex("test").WithQueryString("hello world").WithLimit(0, 5)
+	_, err := client.Profile(ctx, qry, true)
+	require.NoError(t, err)
+
+	agg := NewAggregate().WithIndex("test").AddGroupBy([]string{"@type"})
+	_, err = client.Profile(ctx, agg, false)
+	require.NoError(t, err)
+
+	_, err = client.Profile(ctx, qry.WithTimeout(2*time.Second), false)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"[FT.PROFILE test SEARCH LIMITED QUERY hello world LIMIT 0 5 TIMEOUT 1000]",
+		"[FT.PROFILE test AGGREGATE QUERY * GROUPBY 1 @type TIMEOUT 1000]",
+		"[FT.PROFILE test SEARCH QUERY hello world TIMEOUT 2000 LIMIT 0 5]",
+	}, fake.sent)
+
+	_, err = client.Profile(ctx, NewQuery().WithIndex("test").
+		AddFilter(NewQueryFilter("price").WithMinInclusive(2).WithMaxInclusive(1)), false)
+	require.EqualError(t, err, "ftsearch: FILTER price: min 2 is greater than max 1")
+	require.Len(t, fake.sent, 3)
+}
+
+func TestParseProfile(t *testing.T) {
+	raw := []interface{}{
+		[]interface{}{"Total profile time", "0.5"},
+		[]interface{}{"Parsing time", "0.1"},
+		[]interface{}{"Pipeline creation time", "0.02"},
+		[]interface{}{"Iterators profile",
+			[]interface{}{"Type", "INTERSECT", "Time", "0.03", "Counter", int64(2), "Child iterators",
+				[]interface{}{"Type", "TEXT", "Term", "hello", "Time", "0.01", "Counter", int64(4), "Size", int64(4)},
+				[]interface{}{"Type", "TEXT", "Term", "world", "Time", "0.01", "Counter", int64(3), "Size", int64(3)},
+			},
+		},
+		[]interface{}{"Result processors profile",
+			[]interface{}{"Type", "Index", "Time", "0.04", "Counter", int64(2)},
+			[]interface{}{"Type", "Scorer", "Time", "0.01", "Counter", int64(2)},
+		},
+	}
+
+	profile, err := parseProfile("reply[1]", raw)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, profile.TotalTime)
+	require.Equal(t, 0.1, profile.ParsingTime)
+	require.Equal(t, 0.02, profile.PipelineCreationTime)
+	require.Equal(t, "INTERSECT", profile.Iterators.Type)
+	require.Len(t, profile.Iterators.Children, 2)
+	require.Equal(t, "world", profile.Iterators.Children[1].Term)
+	require.Equal(t, int64(3), profile.Iterators.Children[1].Size)
+	require.Len(t, profile.ResultProcessors, 2)
+	require.Equal(t, "Scorer", profile.ResultProcessors[1].Type)
+	require.Equal(t, 0.04, profile.ResultProcessors[0].Time)
+}
+
+func TestParseProfileShards(t *testing.T) {
+	raw := []interface{}{
+		"Shards", []interface{}{
+			[]interface{}{
+				"Total profile time", "1.5",
+				"Parsing time", "0.2",
+				"Iterators profile", []interface{}{"Type", "TEXT", "Term", "hello", "Time", "0.1", "Counter", int64(1)},
+				"Result processors profile", []interface{}{
+					[]interface{}{"Type", "Index", "Time", "0.1", "Counter", int64(1)},
+				},
+			},
+		},
+		"Coordinator", []interface{}{},
+	}
+
+	profile, err := parseProfile("reply[1]", raw)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, profile.TotalTime)
+	require.Equal(t, "hello", profile.Iterators.Term)
+	require.Len(t, profile.ResultProcessors, 1)
+}
+
+func TestParseProfileMalformed(t *testing.T) {
+	_, err := parseProfile("reply[1]", []interface{}{
+		[]interface{}{"Total profile time", "slow"},
+	})
+	require.EqualError(t, err, "ftsearch: expected a number at reply[1].Total profile time, got string")
+
+	_, err = parseProfile("reply[1]", []interface{}{
+		[]interface{}{"Iterators profile",
+			[]interface{}{"Type", "UNION", "Child iterators", []interface{}{"Type", "TEXT", "Counter", "many"}},
+		},
+	})
+	require.EqualError(t, err, "ftsearch: expected an integer at reply[1].Iterators profile[0].Child iterators[0].Counter, got string")
+
+	_, err = parseProfile("reply[1]", []interface{}{
+		[]interface{}{"Result processors profile", "Index"},
+	})
+	require.EqualError(t, err, "ftsearch: expected an array at reply[1].Result processors profile[0], got string")
+
+	client, _ := newFakeClient(map[string]interface{}{
+		"FT.PROFILE": []interface{}{[]interface{}{int64(0)}},
+	})
+	_, err = client.Profile(context.Background(), NewQuery().WithIndex("test"), false)
+	require.EqualError(t, err, "ftsearch: expected an array at reply[1], got nil")
+}
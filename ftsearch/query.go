@@ -3,11 +3,12 @@ package ftsearch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
-
-	"github.com/go-redis/redis/v8"
+	"strings"
+	"time"
 )
 
 type countedArgs []string
@@ -24,14 +25,45 @@ type query struct {
 	Limit        *queryLimit
 	ReturnFields countedArgs
 	Filters      queryFilterList
+	GeoFilters   queryGeoFilterList
 	InKeys       countedArgs
 	InFields     countedArgs
 	Language     string
 	Slop         int32
 	Summarize    *querySummarize
 	HighLight    *queryHighlight
+	SortBy       *querySortBy
+	Timeout      time.Duration
+	Params       queryParamList
+	Dialect      int32
+	Clauses      []queryClause
+	Scorer       Scorer
+	Expander     string
+	Payload      string
+	WithPayloads bool
 }
 
+// Scorer is a function used to score results
+type Scorer string
+
+const (
+	ScorerTFIDF        Scorer = "TFIDF"
+	ScorerTFIDFDocNorm Scorer = "TFIDF.DOCNORM"
+	ScorerBM25         Scorer = "BM25"
+	ScorerBM25Std      Scorer = "BM25STD"
+	ScorerDisMax       Scorer = "DISMAX"
+	ScorerDocScore     Scorer = "DOCSCORE"
+	ScorerHamming      Scorer = "HAMMING"
+)
+
+// queryParam is a value substituted for $Name in the query string
+type queryParam struct {
+	Name  string
+	Value interface{}
+}
+
+type queryParamList []*queryParam
+
 const (
 	noSlop                   = -100 // impossible value for slop to indicate none set
 	defaultOffset            = 0    // default first value for return offset
@@ -39,61 +71,74 @@ const (
 	defaultSumarizeSeparator = "..."
 	defaultSummarizeLen      = 20
 	defaultSummarizeFrags    = 3
+	defaultHighlightOpen     = "<b>"
+	defaultHighlightClose    = "</b>"
 )
 
+// QueryResult is a single search result. When the query summarizes or
+// highlights, the affected fields in Value hold the server's output and
+// Snippets breaks it down by field.
 type QueryResult struct {
 	Score       float64
 	Value       map[string]string
 	Explanation []interface{}
+	Payload     string
+	Snippets    map[string]Snippet
 }
 
+// QueryResults holds the results of a search. Keys lists the keys in Data
+// in the order the server returned them. Warnings are only sent by
+// servers using RESP3, for example when the query timed out. Partial is
+// set when such a warning shows the server returned the results found
+// before timing out, under the RETURN timeout policy, so it is never set
+// for RESP2 replies, which have no warnings. Under the FAIL policy the
+// search returns an error matching ErrTimeout instead.
 type QueryResults struct {
-	Count int64
-	Data  map[string]QueryResult
+	Count    int64
+	Data     map[string]QueryResult
+	Keys     []string
+	Warnings []string
+	Partial  bool
 }
 
 func (c *Client) Search(ctx context.Context, qry *query) (*QueryResults, error) {
-
-	serialized := qry.serialize()
-	cmd := redis.NewSliceCmd(ctx, serialized...)
-	if err := c.client.Process(ctx, cmd); err != nil {
+	serialized, err := c.searchArgs(ctx, qry)
+	if err != nil {
 		return nil, err
-	} else if rawResults, err := cmd.Result(); err != nil {
+	}
+	if reply, err := c.do(ctx, serialized); err != nil {
 		return nil, err
 	} else {
-		resultSize := qry.resultSize()
-		resultCount := (len(rawResults) - 1) / resultSize
-		results := QueryResults{
-			Count: rawResults[0].(int64),
-			Data:  make(map[string]QueryResult, resultCount),
-		}
-
-		for i := 1; i < len(rawResults); i += resultSize {
-			j := 0
-			var score float64 = 0
-
-			key := rawResults[i+j].(string)
-			j++
-
-			if qry.WithScores {
-				score, _ = strconv.ParseFloat(rawResults[i+j].(string), 64)
-				j++
-			}
-
-			result := QueryResult{
-				Score: score,
-			}
-
-			if !qry.NoContent {
-				result.Value = toMap(rawResults[i+j].([]interface{}))
-				j++
-			}
+		return qry.parseReply(reply)
+	}
+}
 
-			results.Data[key] = result
+// searchArgs validates the query and serializes it, adding the client's
+// TIMEOUT if the query does not set its own
+func (c *Client) searchArgs(ctx context.Context, qry *query) ([]interface{}, error) {
+	if err := qry.validate(); err != nil {
+		return nil, err
+	}
+	serialized := qry.serialize()
+	if qry.Timeout == 0 {
+		serialized = append(serialized, c.timeoutArgs(ctx)...)
+	}
+	return serialized, nil
+}
 
+// timeoutArgs returns the TIMEOUT argument for a search or aggregate which
+// does not set its own - the sooner of the client default and the context deadline.
+func (c *Client) timeoutArgs(ctx context.Context) []interface{} {
+	timeout := c.timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); timeout == 0 || remaining < timeout {
+			timeout = remaining
+		}
+		if timeout <= 0 {
+			timeout = time.Millisecond
 		}
-		return &results, nil
 	}
+	return serializeTimeout(timeout)
 }
 
 /******************************************************************************
@@ -163,6 +208,14 @@ func (q *query) AddFilter(filter *queryFilter) *query {
 	return q
 }
 
+// AddGeoFilter adds a GEOFILTER restricting results to those with the
+// attribute within radius of a point, returning the updated query. The
+// filter is checked when the query is run.
+func (q *query) AddGeoFilter(attribute string, lon float64, lat float64, radius float64, unit GeoUnit) *query {
+	q.GeoFilters = append(q.GeoFilters, NewQueryGeoFilter(attribute, lon, lat, radius, unit))
+	return q
+}
+
 // WithInKeys sets the keys to be searched, limiting the search
 // to only these keys. The updated query is returned.
 func (q *query) WithInKeys(keys []string) *query {
@@ -191,12 +244,68 @@ func (q *query) AddField(field string) *query {
 	return q
 }
 
+// WithSortBy sets the attribute the results are sorted by, returning the
+// updated query
+func (q *query) WithSortBy(attribute string, ascending bool) *query {
+	q.SortBy = NewQuerySortBy(attribute, ascending)
+	return q
+}
+
+// AddParam sets a parameter referred to as $name in the query string,
+// returning the updated query. Parameters need dialect 2 or later.
+func (q *query) AddParam(name string, value interface{}) *query {
+	q.Params = q.Params.set(name, value)
+	return q
+}
+
+// WithDialect sets the query dialect, returning the updated query
+func (q *query) WithDialect(dialect int32) *query {
+	q.Dialect = dialect
+	return q
+}
+
+// WithTimeout sets the time the server may spend on the query, returning
+// the updated query. It is sent in milliseconds.
+func (q *query) WithTimeout(timeout time.Duration) *query {
+	q.Timeout = timeout
+	return q
+}
+
+// WithScorer sets the function used to score results, returning the
+// updated query. HAMMING needs the documents to have binary payloads.
+func (q *query) WithScorer(scorer Scorer) *query {
+	q.Scorer = scorer
+	return q
+}
+
+// WithExpander sets the query expander, such as SYNONYM or a custom
+// extension, returning the updated query
+func (q *query) WithExpander(expander string) *query {
+	q.Expander = expander
+	return q
+}
+
+// WithPayload sets the query payload passed to the scoring function,
+// returning the updated query
+func (q *query) WithPayload(payload string) *query {
+	q.Payload = payload
+	return q
+}
+
 // WithSummarize sets the Summarize member of the query, returning the updated query.
 func (q *query) WithSummarize(s *querySummarize) *query {
 	q.Summarize = s
 	return q
 }
 
+// WithDefaultSummarize summarizes the fields given, or all text fields if
+// none are, with the default fragment count, length and separator,
+// returning the updated query.
+func (q *query) WithDefaultSummarize(fields ...string) *query {
+	q.Summarize = DefaultQuerySummarize().WithFields(fields)
+	return q
+}
+
 // WithHighlight sets the Highlight member of the query, returning the updated query.
 func (q *query) WithHighlight(h *queryHighlight) *query {
 	q.HighLight = h
@@ -206,7 +315,12 @@ func (q *query) WithHighlight(h *queryHighlight) *query {
 // serialize converts a query struct to a slice of  interface{}
 // ready for execution against Redis
 func (q *query) serialize() []interface{} {
-	var args = []interface{}{"FT.SEARCH", q.Index, q.QueryString}
+	queryString := q.QueryString
+	if q.inlineFilters() {
+		queryString = q.filteredQueryString()
+	}
+	queryString, params := q.withClauses(queryString)
+	var args = []interface{}{"FT.SEARCH", q.Index, queryString}
 
 	if q.NoContent {
 		args = append(args, "NOCONTENT")
@@ -224,7 +338,14 @@ func (q *query) serialize() []interface{} {
 		args = append(args, "WITHSCORES")
 	}
 
-	args = append(args, q.Filters.serialize()...)
+	if q.WithPayloads {
+		args = append(args, "WITHPAYLOADS")
+	}
+
+	if !q.inlineFilters() {
+		args = append(args, q.Filters.serialize()...)
+	}
+	args = append(args, q.GeoFilters.serialize()...)
 	args = append(args, q.ReturnFields.serialize("RETURN")...)
 
 	if q.Summarize != nil {
@@ -244,18 +365,149 @@ func (q *query) serialize() []interface{} {
 	args = append(args, q.serializeLanguage()...)
 	args = append(args, q.InKeys.serialize("INKEYS")...)
 	args = append(args, q.InFields.serialize("INFIELDS")...)
+	args = append(args, serializeTimeout(q.Timeout)...)
+
+	if q.Expander != "" {
+		args = append(args, "EXPANDER", q.Expander)
+	}
+
+	if q.Scorer != "" {
+		args = append(args, "SCORER", string(q.Scorer))
+	}
 
 	if q.ExplainScore {
 		args = append(args, "EXPLAINSCORE")
 	}
 
+	if q.Payload != "" {
+		args = append(args, "PAYLOAD", q.Payload)
+	}
+
+	if q.SortBy != nil {
+		args = append(args, q.SortBy.serialize()...)
+	}
+
 	if q.Limit != nil {
 		args = append(args, q.Limit.serialize()...)
 	}
 
+	args = append(args, params.serialize()...)
+
+	if dialect := q.dialect(); dialect != noDialect {
+		args = append(args, "DIALECT", dialect)
+	}
+
 	return args
 }
 
+// validate checks the query for errors which would otherwise only be
+// reported by the server
+func (q *query) validate() error {
+	if q.ExplainScore && !q.WithScores {
+		return errors.New("ftsearch: EXPLAINSCORE requires WITHSCORES")
+	}
+	for _, filter := range q.Filters {
+		if err := filter.validate(); err != nil {
+			return err
+		}
+	}
+	for _, gf := range q.GeoFilters {
+		if err := gf.validate(); err != nil {
+			return err
+		}
+	}
+	for _, clause := range q.Clauses {
+		if err := clause.validate(); err != nil {
+			return err
+		}
+	}
+	if q.HighLight != nil {
+		if err := q.HighLight.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseReply converts an FT.SEARCH reply in either the RESP2 or the RESP3
+// shape into QueryResults
+func (q *query) parseReply(reply interface{}) (*QueryResults, error) {
+	if m, ok := replyMap(reply); ok {
+		return q.parseMap(m)
+	}
+	if rawResults, err := replySlice(reply); err != nil {
+		return nil, err
+	} else {
+		return q.parse(rawResults)
+	}
+}
+
+// parse converts the raw RESP2 results of an FT.SEARCH into QueryResults
+func (q *query) parse(rawResults []interface{}) (*QueryResults, error) {
+	if len(rawResults) == 0 {
+		return nil, newReplyError(elementPath("reply", 0), "an integer", nil)
+	}
+	count, err := decodeInt(elementPath("reply", 0), rawResults[0])
+	if err != nil {
+		return nil, err
+	}
+
+	resultSize := q.resultSize()
+	resultCount := (len(rawResults) - 1) / resultSize
+	results := QueryResults{
+		Count: count,
+		Data:  make(map[string]QueryResult, resultCount),
+	}
+
+	for i := 1; i < len(rawResults); i += resultSize {
+		if i+resultSize > len(rawResults) {
+			return nil, newReplyError(elementPath("reply", len(rawResults)), "a complete result", nil)
+		}
+
+		j := 0
+		var score float64 = 0
+
+		key, err := decodeString(elementPath("reply", i+j), rawResults[i+j])
+		if err != nil {
+			return nil, err
+		}
+		j++
+
+		var explanation []interface{}
+		if q.WithScores {
+			if score, explanation, err = q.decodeScore(elementPath("reply", i+j), rawResults[i+j]); err != nil {
+				return nil, err
+			}
+			j++
+		}
+
+		result := QueryResult{
+			Score:       score,
+			Explanation: explanation,
+		}
+
+		if q.WithPayloads {
+			if result.Payload, err = decodePayload(elementPath("reply", i+j), rawResults[i+j]); err != nil {
+				return nil, err
+			}
+			j++
+		}
+
+		if !q.NoContent {
+			if result.Value, err = decodeFields(elementPath("reply", i+j), rawResults[i+j]); err != nil {
+				return nil, err
+			}
+			result.Snippets = q.snippets(result.Value)
+			j++
+		}
+
+		results.Data[key] = result
+		results.Keys = append(results.Keys, key)
+
+	}
+	return &results, nil
+}
+
 // resultSize uses the query to work out how many entries
 // in the query raw results slice are used per result.
 func (q *query) resultSize() int {
@@ -269,13 +521,93 @@ func (q *query) resultSize() int {
 		count -= 1
 	}
 
-	if q.ExplainScore {
+	if q.WithPayloads {
 		count += 1
 	}
 
 	return count
 }
 
+// decodeScore decodes a result score. With EXPLAINSCORE the server sends
+// the score and its explanation as a pair in place of the score.
+func (q *query) decodeScore(path string, raw interface{}) (float64, []interface{}, error) {
+	if !q.ExplainScore {
+		score, err := decodeFloat(path, raw)
+		return score, nil, err
+	}
+
+	pair, err := decodeArray(path, raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(pair) != 2 {
+		return 0, nil, newReplyError(path, "a score and explanation", raw)
+	}
+	score, err := decodeFloat(elementPath(path, 0), pair[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	if explanation, ok := pair[1].([]interface{}); ok {
+		return score, explanation, nil
+	}
+	return score, []interface{}{pair[1]}, nil
+}
+
+// decodePayload decodes a result payload, which is nil for documents
+// without one
+func decodePayload(path string, raw interface{}) (string, error) {
+	if raw == nil {
+		return "", nil
+	}
+	return decodeString(path, raw)
+}
+
+// inlineFilters reports whether numeric filters are written into the query
+// string rather than as FILTER arguments, which are deprecated from
+// dialect 2
+func (q *query) inlineFilters() bool {
+	return q.dialect() >= 2 && len(q.Filters) > 0
+}
+
+// queryClause is a clause added to the query string when the query is
+// serialized by AddNumeric, AddGeoShape or AddWeightedFields
+type queryClause interface {
+	// build returns the clause, adding any parameters it uses to params
+	build(params queryParamList) (string, queryParamList)
+	// dialect returns the lowest dialect supporting the clause
+	dialect() int32
+	validate() error
+}
+
+// withClauses adds the clauses of the query to the query string given,
+// each of which must also match, returning it with the parameters sent
+func (q *query) withClauses(queryString string) (string, queryParamList) {
+	params := append(queryParamList(nil), q.Params...)
+	for _, qc := range q.Clauses {
+		var clause string
+		if clause, params = qc.build(params); clause == "" {
+			continue
+		}
+		if queryString == "" || queryString == "*" {
+			queryString = clause
+		} else {
+			queryString = fmt.Sprintf("(%s) %s", queryString, clause)
+		}
+	}
+	return queryString, params
+}
+
+// dialect returns the dialect sent, raised if needed for the clauses
+func (q *query) dialect() int32 {
+	dialect := q.Dialect
+	for _, clause := range q.Clauses {
+		if clause.dialect() > dialect {
+			dialect = clause.dialect()
+		}
+	}
+	return dialect
+}
+
 func (q *query) serializeSlop() []interface{} {
 	if q.Slop != noSlop {
 		return []interface{}{"SLOP", q.Slop}
@@ -284,6 +616,16 @@ func (q *query) serializeSlop() []interface{} {
 	}
 }
 
+// serializeTimeout returns the TIMEOUT argument in milliseconds, rounding
+// up so that a short timeout is not sent as 0, which means no timeout
+func serializeTimeout(timeout time.Duration) []interface{} {
+	if timeout > 0 {
+		return []interface{}{"TIMEOUT", int64((timeout + time.Millisecond - 1) / time.Millisecond)}
+	} else {
+		return nil
+	}
+}
+
 func (q *query) serializeLanguage() []interface{} {
 	if q.Language != "" {
 		return []interface{}{"LANGUAGE", q.Language}
@@ -296,7 +638,8 @@ func (q *query) serializeLanguage() []interface{} {
 * Public utilities                                                            *
 ******************************************************************************/
 
-// FilterValue formats a value for use in a filter and returns it
+// FilterValue formats a value for use in a filter and returns it. Values
+// are written with as many digits as are needed to read them back exactly.
 func FilterValue(val float64, exclusive bool) interface{} {
 	prefix := ""
 	if exclusive {
@@ -308,7 +651,7 @@ func FilterValue(val float64, exclusive bool) interface{} {
 	} else if math.IsInf(val, 1) {
 		return prefix + "+inf"
 	} else {
-		return fmt.Sprintf("%s%f", prefix, val)
+		return prefix + strconv.FormatFloat(val, 'g', -1, 64)
 	}
 }
 
@@ -316,16 +659,70 @@ func FilterValue(val float64, exclusive bool) interface{} {
 * Internal utilities                                                          *
 ******************************************************************************/
 
-func toMap(input []interface{}) map[string]string {
+// toMap converts a flat array of names and values at path into a map.
+// Fields with nil values are left out.
+func toMap(path string, input []interface{}) (map[string]string, error) {
+	if len(input)%2 != 0 {
+		return nil, newReplyError(elementPath(path, len(input)), "a field value", nil)
+	}
 
 	results := make(map[string]string, len(input)/2)
-	key := ""
 	for i := 0; i < len(input); i += 2 {
-		key = input[i].(string)
-		value := input[i+1].(string)
-		results[key] = value
+		key, err := decodeString(elementPath(path, i), input[i])
+		if err != nil {
+			return nil, err
+		}
+		if value, ok, err := decodeScalar(elementPath(path, i+1), input[i+1]); err != nil {
+			return nil, err
+		} else if ok {
+			results[key] = value
+		}
+	}
+	return results, nil
+}
+
+// set replaces the value of the parameter, or adds it if the list does
+// not have it, returning the updated list
+func (q queryParamList) set(name string, value interface{}) queryParamList {
+	name = strings.TrimPrefix(name, "$")
+	for _, param := range q {
+		if param.Name == name {
+			param.Value = value
+			return q
+		}
+	}
+	return append(q, &queryParam{Name: name, Value: value})
+}
+
+// unusedName returns the first name made of the prefix and a number which
+// no parameter in the list has
+func (q queryParamList) unusedName(prefix string) string {
+	for n := 0; ; n++ {
+		name := fmt.Sprintf("%s%d", prefix, n)
+		used := false
+		for _, param := range q {
+			if param.Name == name {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return name
+		}
+	}
+}
+
+// serialize converts the parameters to PARAMS arguments
+func (q queryParamList) serialize() []interface{} {
+	if len(q) > 0 {
+		args := []interface{}{"PARAMS", len(q) * 2}
+		for _, param := range q {
+			args = append(args, param.Name, param.Value)
+		}
+		return args
+	} else {
+		return nil
 	}
-	return results
 }
 
 func (c countedArgs) serialize(name string) []interface{} {
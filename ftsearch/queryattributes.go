@@ -0,0 +1,138 @@
+package ftsearch
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/******************************************************************************
+* Query attributes                                                            *
+******************************************************************************/
+
+// queryAttributes is an attribute block, => { $name: value; }, which
+// changes how a sub-expression of a query is matched and scored
+type queryAttributes struct {
+	Attributes []*queryAttribute
+}
+
+// queryAttribute is a single attribute in a block
+type queryAttribute struct {
+	Name  string
+	Value string
+}
+
+// NewQueryAttributes returns an empty attribute block
+func NewQueryAttributes() *queryAttributes {
+	return &queryAttributes{}
+}
+
+// WithWeight sets $weight, which multiplies the score of matches, and
+// returns the updated block
+func (qa *queryAttributes) WithWeight(weight float64) *queryAttributes {
+	return qa.set("weight", strconv.FormatFloat(weight, 'g', -1, 64))
+}
+
+// WithSlop sets $slop, the number of terms allowed between matching
+// terms, and returns the updated block
+func (qa *queryAttributes) WithSlop(slop int32) *queryAttributes {
+	return qa.set("slop", strconv.FormatInt(int64(slop), 10))
+}
+
+// WithInOrder sets $inorder, requiring terms to appear in query order,
+// and returns the updated block
+func (qa *queryAttributes) WithInOrder(inOrder bool) *queryAttributes {
+	return qa.set("inorder", strconv.FormatBool(inOrder))
+}
+
+// WithPhonetic sets $phonetic, enabling or disabling phonetic matching on
+// fields which support it, and returns the updated block
+func (qa *queryAttributes) WithPhonetic(phonetic bool) *queryAttributes {
+	return qa.set("phonetic", strconv.FormatBool(phonetic))
+}
+
+// set adds an attribute or replaces its value if already set
+func (qa *queryAttributes) set(name string, value string) *queryAttributes {
+	for _, attr := range qa.Attributes {
+		if attr.Name == name {
+			attr.Value = value
+			return qa
+		}
+	}
+	qa.Attributes = append(qa.Attributes, &queryAttribute{Name: name, Value: value})
+	return qa
+}
+
+// Apply returns the expression with the attribute block applied, for
+// example (hello world) => { $weight: 5; $slop: 1; }. An empty block
+// leaves the expression unchanged.
+func (qa *queryAttributes) Apply(expression string) string {
+	if len(qa.Attributes) == 0 {
+		return expression
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "(%s) => {", expression)
+	for _, attr := range qa.Attributes {
+		fmt.Fprintf(&b, " $%s: %s;", attr.Name, attr.Value)
+	}
+	b.WriteString(" }")
+	return b.String()
+}
+
+// String returns the attribute block on its own
+func (qa *queryAttributes) String() string {
+	return strings.TrimPrefix(qa.Apply(""), "() => ")
+}
+
+// WeightedFieldsQuery returns a query matching the expression in any of
+// the fields given, each match weighted by the field's weight. Fields are
+// written in name order, for example
+// ((@body:(go)) => { $weight: 1; } | (@title:(go)) => { $weight: 5; }).
+// With no weights the expression is returned unchanged.
+func WeightedFieldsQuery(expression string, weights map[string]float64) string {
+	if len(weights) == 0 {
+		return expression
+	}
+
+	fields := make([]string, 0, len(weights))
+	for field := range weights {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	clauses := make([]string, 0, len(fields))
+	for _, field := range fields {
+		clause := fmt.Sprintf("@%s:(%s)", strings.TrimPrefix(field, "@"), expression)
+		clauses = append(clauses, NewQueryAttributes().WithWeight(weights[field]).Apply(clause))
+	}
+	return "(" + strings.Join(clauses, " | ") + ")"
+}
+
+// AddWeightedFields adds a clause matching the expression in any of the
+// fields given, weighted per field, to the query string. The clause is
+// written when the query is serialized, so the query string may be set
+// before or after. The updated query is returned.
+func (q *query) AddWeightedFields(expression string, weights map[string]float64) *query {
+	q.Clauses = append(q.Clauses, &queryWeightedFields{Expression: expression, Weights: weights})
+	return q
+}
+
+// queryWeightedFields is a clause added with AddWeightedFields
+type queryWeightedFields struct {
+	Expression string
+	Weights    map[string]float64
+}
+
+func (wf *queryWeightedFields) build(params queryParamList) (string, queryParamList) {
+	return WeightedFieldsQuery(wf.Expression, wf.Weights), params
+}
+
+func (wf *queryWeightedFields) dialect() int32 {
+	return noDialect
+}
+
+func (wf *queryWeightedFields) validate() error {
+	return nil
+}
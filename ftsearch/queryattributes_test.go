@@ -0,0 +1,27 @@
+package ftsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryAttributes(t *testing.T) {
+	attrs := NewQueryAttributes().WithWeight(2.5).WithSlop(1).WithInOrder(true).WithPhonetic(false).WithWeight(5)
+	require.Equal(t, "(hello world) => { $weight: 5; $slop: 1; $inorder: true; $phonetic: false; }", attrs.Apply("hello world"))
+	require.Equal(t, "{ $weight: 5; $slop: 1; $inorder: true; $phonetic: false; }", attrs.String())
+	require.Equal(t, "hello", NewQueryAttributes().Apply("hello"))
+}
+
+func TestWeightedFields(t *testing.T) {
+	weights := map[string]float64{"title": 5, "@body": 1}
+	require.Equal(t, "((@body:(go)) => { $weight: 1; } | (@title:(go)) => { $weight: 5; })", WeightedFieldsQuery("go", weights))
+	require.Equal(t, "[FT.SEARCH idx (@genre:{tech}) ((@body:(go)) => { $weight: 1; } | (@title:(go)) => { $weight: 5; })]",
+		NewQuery().WithIndex("idx").WithQueryString("@genre:{tech}").AddWeightedFields("go", weights).String())
+
+	require.Equal(t, "go", WeightedFieldsQuery("go", nil))
+	require.Equal(t, "[FT.SEARCH idx (@genre:{tech}) go]",
+		NewQuery().WithIndex("idx").WithQueryString("@genre:{tech}").AddWeightedFields("go", map[string]float64{}).String())
+	require.Equal(t, "[FT.SEARCH idx (@genre:{tech}) go]",
+		NewQuery().WithIndex("idx").AddWeightedFields("go", nil).WithQueryString("@genre:{tech}").String())
+}
@@ -2,7 +2,12 @@ package ftsearch
 
 // Functions operating on QueryFilter structs                                  *
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
 
 type queryFilter struct {
 	Attribute string
@@ -59,3 +64,136 @@ func (q queryFilterList) serialize() []interface{} {
 		return nil
 	}
 }
+
+// QueryString returns the filter in query syntax, @attribute:[min max],
+// which replaces FILTER from dialect 2
+func (qf *queryFilter) QueryString() string {
+	return fmt.Sprintf("@%s:[%v %v]", strings.TrimPrefix(qf.Attribute, "@"), qf.Min, qf.Max)
+}
+
+// validate checks the bounds are numbers and the range is not inverted
+func (qf *queryFilter) validate() error {
+	min, err := filterBound(qf.Min)
+	if err != nil {
+		return fmt.Errorf("ftsearch: FILTER %s: %w", qf.Attribute, err)
+	}
+	max, err := filterBound(qf.Max)
+	if err != nil {
+		return fmt.Errorf("ftsearch: FILTER %s: %w", qf.Attribute, err)
+	}
+	if min > max {
+		return fmt.Errorf("ftsearch: FILTER %s: min %v is greater than max %v", qf.Attribute, qf.Min, qf.Max)
+	}
+	return nil
+}
+
+// filterBound parses a value written by FilterValue or set directly
+func filterBound(bound interface{}) (float64, error) {
+	var val float64
+	switch b := bound.(type) {
+	case float64:
+		val = b
+	case int:
+		val = float64(b)
+	case int64:
+		val = float64(b)
+	case string:
+		var err error
+		if val, err = strconv.ParseFloat(strings.TrimPrefix(b, "("), 64); err != nil {
+			return 0, fmt.Errorf("bound %q is not a number", b)
+		}
+	default:
+		return 0, fmt.Errorf("bound %v is not a number", bound)
+	}
+
+	if math.IsNaN(val) {
+		return 0, fmt.Errorf("bound is NaN")
+	}
+	return val, nil
+}
+
+/******************************************************************************
+* Numeric comparisons                                                         *
+******************************************************************************/
+
+// NumericOperator compares a NUMERIC attribute with a value in a query
+type NumericOperator string
+
+const (
+	Equal        NumericOperator = "=="
+	NotEqual     NumericOperator = "!="
+	Greater      NumericOperator = ">"
+	GreaterEqual NumericOperator = ">="
+	Less         NumericOperator = "<"
+	LessEqual    NumericOperator = "<="
+)
+
+// valid checks the operator is one of those NumericQuery can write
+func (op NumericOperator) valid() bool {
+	switch op {
+	case Equal, NotEqual, Greater, GreaterEqual, Less, LessEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// numericEqualityDialect is the lowest dialect supporting single value
+// ranges, which Equal and NotEqual are written as
+const numericEqualityDialect = 4
+
+// NumericQuery returns the query string clause comparing a NUMERIC
+// attribute with a value. Equal and NotEqual are written as @attribute:[val]
+// and -@attribute:[val], which need dialect 4; the other operators as
+// ranges, which work with any dialect. An unknown operator returns an
+// empty clause.
+func NumericQuery(attribute string, op NumericOperator, val float64) string {
+	attribute = strings.TrimPrefix(attribute, "@")
+	switch op {
+	case Equal:
+		return fmt.Sprintf("@%s:[%v]", attribute, FilterValue(val, false))
+	case NotEqual:
+		return fmt.Sprintf("-@%s:[%v]", attribute, FilterValue(val, false))
+	case Greater, GreaterEqual:
+		return fmt.Sprintf("@%s:[%v +inf]", attribute, FilterValue(val, op == Greater))
+	case Less, LessEqual:
+		return fmt.Sprintf("@%s:[-inf %v]", attribute, FilterValue(val, op == Less))
+	default:
+		return ""
+	}
+}
+
+// AddNumeric adds a clause comparing a NUMERIC attribute with a value to
+// the query string, raising the dialect to 4 for Equal and NotEqual. The
+// clause is written when the query is serialized, so the query string may
+// be set before or after. An unknown operator adds nothing and fails
+// validation. The updated query is returned.
+func (q *query) AddNumeric(attribute string, op NumericOperator, val float64) *query {
+	q.Clauses = append(q.Clauses, &queryNumeric{Attribute: attribute, Operator: op, Value: val})
+	return q
+}
+
+// queryNumeric is a comparison added with AddNumeric
+type queryNumeric struct {
+	Attribute string
+	Operator  NumericOperator
+	Value     float64
+}
+
+func (qn *queryNumeric) build(params queryParamList) (string, queryParamList) {
+	return NumericQuery(qn.Attribute, qn.Operator, qn.Value), params
+}
+
+func (qn *queryNumeric) dialect() int32 {
+	if qn.Operator == Equal || qn.Operator == NotEqual {
+		return numericEqualityDialect
+	}
+	return noDialect
+}
+
+func (qn *queryNumeric) validate() error {
+	if !qn.Operator.valid() {
+		return fmt.Errorf("ftsearch: %s: unknown numeric operator %q", qn.Attribute, qn.Operator)
+	}
+	return nil
+}
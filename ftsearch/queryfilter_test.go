@@ -0,0 +1,58 @@
+package ftsearch
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterValue(t *testing.T) {
+	require.Equal(t, "1e-09", FilterValue(1e-9, false))
+	require.Equal(t, "(1.5e+21", FilterValue(1.5e21, true))
+	require.Equal(t, "2.5", FilterValue(2.5, false))
+	require.Equal(t, "(-inf", FilterValue(math.Inf(-1), true))
+}
+
+func TestFilterSerialize(t *testing.T) {
+	filter := NewQueryFilter("price").WithMinInclusive(10).WithMaxExclusive(20.5)
+	require.Equal(t, "[FT.SEARCH idx shoes FILTER price 10 (20.5]",
+		NewQuery().WithIndex("idx").WithQueryString("shoes").AddFilter(filter).String())
+	require.Equal(t, "[FT.SEARCH idx (shoes) (@price:[10 (20.5]) DIALECT 2]",
+		NewQuery().WithIndex("idx").WithQueryString("shoes").AddFilter(filter).WithDialect(2).String())
+}
+
+func TestNumericQuery(t *testing.T) {
+	require.Equal(t, "@price:[10]", NumericQuery("@price", Equal, 10))
+	require.Equal(t, "-@price:[10]", NumericQuery("price", NotEqual, 10))
+	require.Equal(t, "@price:[(10 +inf]", NumericQuery("price", Greater, 10))
+	require.Equal(t, "@price:[-inf 10]", NumericQuery("price", LessEqual, 10))
+
+	require.Equal(t, "[FT.SEARCH idx (shoes) @price:[(10 +inf]]",
+		NewQuery().WithIndex("idx").WithQueryString("shoes").AddNumeric("price", Greater, 10).String())
+	require.Equal(t, "[FT.SEARCH idx @size:[9] DIALECT 4]",
+		NewQuery().WithIndex("idx").AddNumeric("size", Equal, 9).String())
+	require.Equal(t, "[FT.SEARCH idx (hello) @price:[(10 +inf]]",
+		NewQuery().WithIndex("idx").AddNumeric("price", Greater, 10).WithQueryString("hello").String())
+	require.Equal(t, "[FT.SEARCH idx ((@price:[1 +inf]) (@size:[-inf 5])) -@size:[9] DIALECT 4]",
+		NewQuery().WithIndex("idx").AddFilter(NewQueryFilter("price").WithMinInclusive(1)).
+			AddFilter(NewQueryFilter("size").WithMaxInclusive(5)).AddNumeric("size", NotEqual, 9).String())
+}
+
+func TestFilterValidate(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{"FT.SEARCH": []interface{}{int64(0)}})
+	_, err := client.Search(context.Background(), NewQuery().WithIndex("idx").
+		AddFilter(NewQueryFilter("price").WithMinInclusive(20).WithMaxInclusive(10)))
+	require.EqualError(t, err, "ftsearch: FILTER price: min 20 is greater than max 10")
+
+	_, err = client.Search(context.Background(), NewQuery().WithIndex("idx").
+		AddFilter(&queryFilter{Attribute: "price", Min: "low", Max: "+inf"}))
+	require.EqualError(t, err, `ftsearch: FILTER price: bound "low" is not a number`)
+
+	qry := NewQuery().WithIndex("idx").WithQueryString("shoes").AddNumeric("price", "=~", 10)
+	require.Equal(t, "shoes", qry.QueryString)
+	_, err = client.Search(context.Background(), qry)
+	require.EqualError(t, err, `ftsearch: price: unknown numeric operator "=~"`)
+	require.Empty(t, fake.sent)
+}
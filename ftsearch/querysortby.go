@@ -0,0 +1,33 @@
+package ftsearch
+
+import "strings"
+
+/******************************************************************************
+* Functions operating on QuerySortBy structs                                  *
+******************************************************************************/
+
+// querySortBy defines the attribute results are sorted by
+type querySortBy struct {
+	Attribute string
+	Ascending bool
+}
+
+// NewQuerySortBy returns an initialized QuerySortBy struct
+func NewQuerySortBy(attribute string, ascending bool) *querySortBy {
+	return &querySortBy{Attribute: attribute, Ascending: ascending}
+}
+
+// Serialize the sort for output
+func (qs *querySortBy) serialize() []interface{} {
+	if qs.Ascending {
+		return []interface{}{"SORTBY", qs.Attribute, "ASC"}
+	} else {
+		return []interface{}{"SORTBY", qs.Attribute, "DESC"}
+	}
+}
+
+// field returns the sorted attribute's name without any leading @, as it
+// appears in results and FILTER arguments
+func (qs *querySortBy) field() string {
+	return strings.TrimPrefix(qs.Attribute, "@")
+}
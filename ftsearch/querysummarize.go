@@ -1,6 +1,14 @@
 package ftsearch
 
-// Functions and structs used to set up summarization and highlighting.
+// Functions and structs used to set up summarization and highlighting and
+// to break down the snippets returned.
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
 
 type querySummarize struct {
 	Fields    countedArgs
@@ -61,13 +69,16 @@ func (s *querySummarize) AddField(field string) *querySummarize {
 	return s
 }
 
-// serialize prepares the summarisation to be passed to Redis.
+// serialize prepares the summarisation to be passed to Redis. An empty
+// separator is left out so the server uses its default.
 func (s *querySummarize) serialize() []interface{} {
 	args := []interface{}{"SUMMARIZE"}
 	args = append(args, s.Fields.serialize("FIELDS")...)
 	args = append(args, "FRAGS", s.Frags)
 	args = append(args, "LEN", s.Len)
-	args = append(args, "SEPARATOR", s.Separator)
+	if s.Separator != "" {
+		args = append(args, "SEPARATOR", s.Separator)
+	}
 	return args
 }
 
@@ -94,14 +105,37 @@ func (h *queryHighlight) AddField(field string) *queryHighlight {
 }
 
 // SetTags sets the start and end tags. Both must be non empty or
-// both empty. This is not enforced in this code to keep the API consistent
-// but will lead to a Redis error if not set correctly.
+// both empty, and an HTML start tag must be closed by the matching end
+// tag; searches with tags which do not match fail before reaching Redis.
 func (h *queryHighlight) SetTags(open string, close string) *queryHighlight {
 	h.OpenTag = open
 	h.CloseTag = close
 	return h
 }
 
+// htmlOpenTag matches an HTML start tag, capturing the element name
+var htmlOpenTag = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9-]*)(\s[^<>]*)?>$`)
+
+// validate checks the tags are set together and, if the open tag is an
+// HTML element, that the close tag ends the same element
+func (h *queryHighlight) validate() error {
+	if (h.OpenTag == "") != (h.CloseTag == "") {
+		return fmt.Errorf("ftsearch: HIGHLIGHT tags must both be set or both be empty, got %q and %q", h.OpenTag, h.CloseTag)
+	}
+	if m := htmlOpenTag.FindStringSubmatch(h.OpenTag); m != nil && !strings.EqualFold(h.CloseTag, "</"+m[1]+">") {
+		return fmt.Errorf("ftsearch: HIGHLIGHT close tag %q does not match open tag %q", h.CloseTag, h.OpenTag)
+	}
+	return nil
+}
+
+// tags returns the tags the server wraps matches in
+func (h *queryHighlight) tags() (string, string) {
+	if h.OpenTag == "" {
+		return defaultHighlightOpen, defaultHighlightClose
+	}
+	return h.OpenTag, h.CloseTag
+}
+
 // serialize prepares the highlighting to be passed to Redis.
 func (h *queryHighlight) serialize() []interface{} {
 	args := []interface{}{"HIGHLIGHT"}
@@ -111,3 +145,119 @@ func (h *queryHighlight) serialize() []interface{} {
 	}
 	return args
 }
+
+/******************************************************************************
+* Snippets                                                                    *
+******************************************************************************/
+
+// Snippet is a summarized or highlighted field value. Text is the value as
+// returned. Fragments holds the summary fragments, or the whole text if the
+// field was not summarized, and Terms the highlighted matches in order.
+type Snippet struct {
+	Text      string
+	Fragments []string
+	Terms     []string
+	OpenTag   string
+	CloseTag  string
+}
+
+// Plain returns the text with the highlight tags removed
+func (s Snippet) Plain() string {
+	if s.OpenTag == "" {
+		return s.Text
+	}
+	return strings.NewReplacer(s.OpenTag, "", s.CloseTag, "").Replace(s.Text)
+}
+
+// HTML returns the text escaped for use in HTML with the highlight tags
+// left in place. The document text is not escaped by Redis, so the Text of
+// a snippet is not safe to render directly.
+func (s Snippet) HTML() string {
+	if s.OpenTag == "" {
+		return html.EscapeString(s.Text)
+	}
+
+	var b strings.Builder
+	for n, outer := range strings.Split(s.Text, s.OpenTag) {
+		if n > 0 {
+			b.WriteString(s.OpenTag)
+		}
+		for m, inner := range strings.Split(outer, s.CloseTag) {
+			if m > 0 {
+				b.WriteString(s.CloseTag)
+			}
+			b.WriteString(html.EscapeString(inner))
+		}
+	}
+	return b.String()
+}
+
+// snippets breaks down the summarized and highlighted fields of a result.
+// A summary or highlight listing no fields applies to every field
+// returned, as the server applies it to every text field.
+func (q *query) snippets(value map[string]string) map[string]Snippet {
+	if q.Summarize == nil && q.HighLight == nil || len(value) == 0 {
+		return nil
+	}
+
+	snippets := make(map[string]Snippet)
+	for name, text := range value {
+		summarized := q.Summarize != nil && appliesTo(q.Summarize.Fields, name)
+		highlighted := q.HighLight != nil && appliesTo(q.HighLight.Fields, name)
+		if summarized || highlighted {
+			snippets[name] = q.snippet(text, summarized, highlighted)
+		}
+	}
+	return snippets
+}
+
+// appliesTo reports whether a SUMMARIZE or HIGHLIGHT field list covers the
+// field, an empty list covering all of them
+func appliesTo(fields countedArgs, field string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range fields {
+		if strings.TrimPrefix(f, "@") == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *query) snippet(text string, summarized, highlighted bool) Snippet {
+	s := Snippet{Text: text}
+
+	if summarized {
+		separator := q.Summarize.Separator
+		if separator == "" {
+			separator = defaultSumarizeSeparator
+		}
+		for _, fragment := range strings.Split(text, separator) {
+			if fragment = strings.TrimSpace(fragment); fragment != "" {
+				s.Fragments = append(s.Fragments, fragment)
+			}
+		}
+	} else {
+		s.Fragments = []string{text}
+	}
+
+	if highlighted {
+		s.OpenTag, s.CloseTag = q.HighLight.tags()
+		rest := text
+		for {
+			start := strings.Index(rest, s.OpenTag)
+			if start < 0 {
+				break
+			}
+			rest = rest[start+len(s.OpenTag):]
+			end := strings.Index(rest, s.CloseTag)
+			if end < 0 {
+				break
+			}
+			s.Terms = append(s.Terms, rest[:end])
+			rest = rest[end+len(s.CloseTag):]
+		}
+	}
+	return s
+}
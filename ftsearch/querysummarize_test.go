@@ -0,0 +1,81 @@
+package ftsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSummarize(t *testing.T) {
+	require.Equal(t, "[FT.SEARCH idx go SUMMARIZE FIELDS 1 body FRAGS 3 LEN 20 SEPARATOR ...]",
+		NewQuery().WithIndex("idx").WithQueryString("go").WithDefaultSummarize("body").String())
+	require.Equal(t, "[FT.SEARCH idx go SUMMARIZE FIELDS 1 body FRAGS 2 LEN 5]",
+		NewQuery().WithIndex("idx").WithQueryString("go").
+			WithSummarize(NewQuerySummarize().AddField("body").WithFrags(2).WithLen(5)).String())
+}
+
+func TestSnippets(t *testing.T) {
+	reply := []interface{}{int64(1), "doc:1", []interface{}{
+		"title", "Go & <Rust>",
+		"body", "learn <b>go</b> fast... why <b>go</b> & friends... ",
+	}}
+	client, _ := newFakeClient(map[string]interface{}{"FT.SEARCH": reply})
+
+	results, err := client.Search(context.Background(), NewQuery().WithIndex("idx").WithQueryString("go").
+		WithDefaultSummarize("body").WithHighlight(NewQueryHighlight().AddField("body")))
+	require.NoError(t, err)
+
+	require.Len(t, results.Data["doc:1"].Snippets, 1)
+	body := results.Data["doc:1"].Snippets["body"]
+	require.Equal(t, []string{"learn <b>go</b> fast", "why <b>go</b> & friends"}, body.Fragments)
+	require.Equal(t, []string{"go", "go"}, body.Terms)
+	require.Equal(t, "learn go fast... why go & friends... ", body.Plain())
+	require.Equal(t, "learn <b>go</b> fast... why <b>go</b> &amp; friends... ", body.HTML())
+
+	results, err = client.Search(context.Background(), NewQuery().WithIndex("idx").
+		WithHighlight(NewQueryHighlight().SetTags("[", "]")))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Go & <Rust>"}, results.Data["doc:1"].Snippets["title"].Fragments)
+	require.Equal(t, "Go &amp; &lt;Rust&gt;", results.Data["doc:1"].Snippets["title"].HTML())
+}
+
+func TestSnippetsDisjointFields(t *testing.T) {
+	reply := []interface{}{int64(1), "doc:1", []interface{}{
+		"title", "Go... and <b>Rust</b>",
+		"body", "learn go fast... why go",
+		"notes", "plain",
+	}}
+	client, _ := newFakeClient(map[string]interface{}{"FT.SEARCH": reply})
+
+	results, err := client.Search(context.Background(), NewQuery().WithIndex("idx").WithQueryString("go").
+		WithSummarize(NewQuerySummarize().AddField("body").WithFrags(2).WithLen(5)).
+		WithHighlight(NewQueryHighlight().AddField("@title")))
+	require.NoError(t, err)
+
+	snippets := results.Data["doc:1"].Snippets
+	require.Len(t, snippets, 2)
+	require.Equal(t, []string{"Go... and <b>Rust</b>"}, snippets["title"].Fragments)
+	require.Equal(t, []string{"Rust"}, snippets["title"].Terms)
+	require.Equal(t, []string{"learn go fast", "why go"}, snippets["body"].Fragments)
+	require.Empty(t, snippets["body"].Terms)
+	require.Equal(t, "", snippets["body"].OpenTag)
+	require.Equal(t, "learn go fast... why go", snippets["body"].Plain())
+}
+
+func TestHighlightValidate(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{"FT.SEARCH": []interface{}{int64(0)}})
+
+	_, err := client.Search(context.Background(), NewQuery().WithIndex("idx").
+		WithHighlight(NewQueryHighlight().SetTags("<em>", "")))
+	require.EqualError(t, err, `ftsearch: HIGHLIGHT tags must both be set or both be empty, got "<em>" and ""`)
+
+	_, err = client.Search(context.Background(), NewQuery().WithIndex("idx").
+		WithHighlight(NewQueryHighlight().SetTags(`<span class="hit">`, "</em>")))
+	require.EqualError(t, err, `ftsearch: HIGHLIGHT close tag "</em>" does not match open tag "<span class=\"hit\">"`)
+	require.Empty(t, fake.sent)
+
+	_, err = client.Search(context.Background(), NewQuery().WithIndex("idx").
+		WithHighlight(NewQueryHighlight().SetTags(`<span class="hit">`, "</span>")))
+	require.NoError(t, err)
+}
@@ -0,0 +1,248 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+)
+
+// Fixture is the content of a fixture file
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded command. Error holds the message of an
+// error returned by the server in place of a reply.
+type Interaction struct {
+	Args  []string `json:"args"`
+	Reply *Value   `json:"reply,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// Value is a reply encoded so that its type survives the trip through JSON
+type Value struct {
+	Type   string  `json:"type"`
+	String string  `json:"string,omitempty"`
+	Int    int64   `json:"int,omitempty"`
+	Float  float64 `json:"float,omitempty"`
+	Bool   bool    `json:"bool,omitempty"`
+	Array  []Value `json:"array,omitempty"`
+}
+
+const (
+	typeNil    = "nil"
+	typeString = "string"
+	typeInt    = "int"
+	typeFloat  = "float"
+	typeBool   = "bool"
+	typeArray  = "array"
+	typeMap    = "map" // array holds alternating keys and values
+	typeError  = "error"
+)
+
+// ServerError is a replayed error returned by the server
+type ServerError string
+
+func (e ServerError) Error() string { return string(e) }
+
+// RedisError marks the error as coming from the server, as go-redis does
+func (e ServerError) RedisError() {}
+
+// Load reads a fixture file
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := &Fixture{}
+	if err := json.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("replay: reading %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Save writes the fixture file, creating its directory if needed
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// isServerError checks for errors sent by the server, which are recorded,
+// as opposed to network or context errors, which are not.
+func isServerError(err error) bool {
+	_, ok := err.(interface{ RedisError() })
+	return ok
+}
+
+func newInteraction(args []string, val interface{}, err error) (Interaction, error) {
+	interaction := Interaction{Args: args}
+	if err != nil {
+		interaction.Error = err.Error()
+		return interaction, nil
+	}
+
+	reply, err := Encode(val)
+	if err != nil {
+		return interaction, err
+	}
+	interaction.Reply = &reply
+	return interaction, nil
+}
+
+func (i Interaction) result() (interface{}, error) {
+	if i.Error != "" {
+		return nil, ServerError(i.Error)
+	}
+	if i.Reply == nil {
+		return nil, nil
+	}
+	return i.Reply.Decode(), nil
+}
+
+// Encode converts a reply into a Value
+func Encode(reply interface{}) (Value, error) {
+	switch r := reply.(type) {
+	case nil:
+		return Value{Type: typeNil}, nil
+	case string:
+		return Value{Type: typeString, String: r}, nil
+	case int64:
+		return Value{Type: typeInt, Int: r}, nil
+	case float64:
+		return Value{Type: typeFloat, Float: r}, nil
+	case bool:
+		return Value{Type: typeBool, Bool: r}, nil
+	case error:
+		return Value{Type: typeError, String: r.Error()}, nil
+	case []interface{}:
+		value := Value{Type: typeArray, Array: make([]Value, len(r))}
+		for n, item := range r {
+			encoded, err := Encode(item)
+			if err != nil {
+				return value, err
+			}
+			value.Array[n] = encoded
+		}
+		return value, nil
+	case map[interface{}]interface{}:
+		pairs := make([]interface{}, 0, len(r)*2)
+		for key, item := range r {
+			pairs = append(pairs, key, item)
+		}
+		return encodeMap(pairs)
+	case map[string]interface{}:
+		pairs := make([]interface{}, 0, len(r)*2)
+		for key, item := range r {
+			pairs = append(pairs, key, item)
+		}
+		return encodeMap(pairs)
+	default:
+		return Value{}, fmt.Errorf("replay: cannot record reply of type %T", reply)
+	}
+}
+
+// encodeMap encodes map entries sorted by key so that fixture files
+// are stable
+func encodeMap(pairs []interface{}) (Value, error) {
+	value := Value{Type: typeMap, Array: make([]Value, len(pairs))}
+	for n, item := range pairs {
+		encoded, err := Encode(item)
+		if err != nil {
+			return value, err
+		}
+		value.Array[n] = encoded
+	}
+
+	entries := make([][2]Value, len(pairs)/2)
+	for n := range entries {
+		entries[n] = [2]Value{value.Array[2*n], value.Array[2*n+1]}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return fmt.Sprint(entries[i][0]) < fmt.Sprint(entries[j][0])
+	})
+	for n, entry := range entries {
+		value.Array[2*n], value.Array[2*n+1] = entry[0], entry[1]
+	}
+	return value, nil
+}
+
+// Decode converts a Value back into a reply of the types go-redis uses.
+// Maps are returned as map[interface{}]interface{}.
+func (v Value) Decode() interface{} {
+	switch v.Type {
+	case typeString:
+		return v.String
+	case typeInt:
+		return v.Int
+	case typeFloat:
+		return v.Float
+	case typeBool:
+		return v.Bool
+	case typeError:
+		return ServerError(v.String)
+	case typeArray:
+		array := make([]interface{}, len(v.Array))
+		for n, item := range v.Array {
+			array[n] = item.Decode()
+		}
+		return array
+	case typeMap:
+		m := make(map[interface{}]interface{}, len(v.Array)/2)
+		for n := 0; n+1 < len(v.Array); n += 2 {
+			m[v.Array[n].Decode()] = v.Array[n+1].Decode()
+		}
+		return m
+	default:
+		return nil
+	}
+}
+
+// Refresh sends every command in the fixture file at path to live, in
+// order, replacing the recorded replies. It reports whether any reply
+// changed and only writes the file if save is set.
+func Refresh(ctx context.Context, path string, live ftsearch.Executor, save bool) (bool, error) {
+	fixture, err := Load(path)
+	if err != nil {
+		return false, err
+	}
+
+	refreshed := &Fixture{Interactions: make([]Interaction, 0, len(fixture.Interactions))}
+	for _, recorded := range fixture.Interactions {
+		args := make([]interface{}, len(recorded.Args))
+		for n, arg := range recorded.Args {
+			args[n] = arg
+		}
+
+		val, err := live.Do(ctx, args...)
+		if err != nil && !isServerError(err) {
+			return false, err
+		}
+
+		interaction, err := newInteraction(recorded.Args, val, err)
+		if err != nil {
+			return false, err
+		}
+		refreshed.Interactions = append(refreshed.Interactions, interaction)
+	}
+
+	before, _ := json.Marshal(fixture)
+	after, _ := json.Marshal(refreshed)
+	changed := !bytes.Equal(before, after)
+	if changed && save {
+		return changed, refreshed.Save(path)
+	}
+	return changed, nil
+}
@@ -0,0 +1,222 @@
+// Package replay records the commands sent by an ftsearch.Client, together
+// with the replies received, into fixture files and replays them so that
+// code using the client can be tested without a Redis server.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+)
+
+// Mode controls whether commands are replayed from the fixture file or
+// sent to a live executor and recorded.
+type Mode int
+
+const (
+	// Replay only replays recorded commands. Any other command fails
+	// with ErrUnrecorded.
+	Replay Mode = iota
+	// Record sends every command to the live executor and records it,
+	// replacing the fixture file when saved.
+	Record
+	// ReplayOrRecord replays recorded commands and sends any others to
+	// the live executor, adding them to the fixture file.
+	ReplayOrRecord
+)
+
+// ModeEnv is the environment variable read by ModeFromEnv
+const ModeEnv = "FTSEARCH_FIXTURES"
+
+// ErrUnrecorded is returned in Replay mode for commands not in the fixture file
+var ErrUnrecorded = errors.New("replay: command not recorded")
+
+// Executor is an ftsearch.BatchExecutor replaying or recording commands.
+// Identical commands are replayed in the order they were recorded. The
+// value of a TIMEOUT argument is ignored when matching searches and
+// aggregates, as the client derives it from the context deadline.
+type Executor struct {
+	path    string
+	mode    Mode
+	live    ftsearch.Executor
+	mu      sync.Mutex
+	fixture *Fixture
+	pending map[string][]Interaction
+	dirty   bool
+}
+
+var _ ftsearch.BatchExecutor = (*Executor)(nil)
+
+// ModeFromEnv returns the mode named by FTSEARCH_FIXTURES - "record",
+// "update" (ReplayOrRecord) or anything else for Replay.
+func ModeFromEnv() Mode {
+	switch strings.ToLower(os.Getenv(ModeEnv)) {
+	case "record":
+		return Record
+	case "update":
+		return ReplayOrRecord
+	default:
+		return Replay
+	}
+}
+
+// New returns an executor for the fixture file at path. live is only
+// used when recording and may be nil in Replay mode. A missing fixture
+// file is treated as empty.
+func New(path string, mode Mode, live ftsearch.Executor) (*Executor, error) {
+	if mode != Replay && live == nil {
+		return nil, errors.New("replay: a live executor is needed to record")
+	}
+
+	e := &Executor{
+		path:    path,
+		mode:    mode,
+		live:    live,
+		fixture: &Fixture{},
+		pending: make(map[string][]Interaction),
+	}
+
+	if mode != Record {
+		fixture, err := Load(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		} else if err == nil {
+			e.fixture = fixture
+		}
+		for _, interaction := range e.fixture.Interactions {
+			key := commandKey(interaction.Args)
+			e.pending[key] = append(e.pending[key], interaction)
+		}
+	}
+
+	return e, nil
+}
+
+// Do replays the command or sends it to the live executor, depending on
+// the mode.
+func (e *Executor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	tokens := ftsearch.FormatArgs(args)
+	key := commandKey(tokens)
+
+	e.mu.Lock()
+	if e.mode != Record {
+		if queue := e.pending[key]; len(queue) > 0 {
+			e.pending[key] = queue[1:]
+			e.mu.Unlock()
+			return queue[0].result()
+		}
+	}
+	e.mu.Unlock()
+
+	if e.mode == Replay {
+		return nil, fmt.Errorf("%w: %s", ErrUnrecorded, strings.Join(tokens, " "))
+	}
+
+	val, err := e.live.Do(ctx, args...)
+	if err != nil && !isServerError(err) {
+		return nil, err
+	}
+
+	interaction, encodeErr := newInteraction(tokens, val, err)
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+
+	e.mu.Lock()
+	e.fixture.Interactions = append(e.fixture.Interactions, interaction)
+	e.dirty = true
+	e.mu.Unlock()
+
+	return val, err
+}
+
+// DoMulti runs each command through Do. Replayed batches have no round
+// trips to save so are not sent to the live executor as a batch.
+func (e *Executor) DoMulti(ctx context.Context, cmds ...[]interface{}) []ftsearch.Reply {
+	replies := make([]ftsearch.Reply, len(cmds))
+	for n, args := range cmds {
+		replies[n].Val, replies[n].Err = e.Do(ctx, args...)
+	}
+	return replies
+}
+
+// Save writes the fixture file if any commands were recorded
+func (e *Executor) Save() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.dirty {
+		return nil
+	}
+	if err := e.fixture.Save(e.path); err != nil {
+		return err
+	}
+	e.dirty = false
+	return nil
+}
+
+// Unused returns the recorded commands which have not been replayed,
+// sorted. Tests can use it to check that every expected command was sent.
+func (e *Executor) Unused() [][]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var unused [][]string
+	for _, queue := range e.pending {
+		for _, interaction := range queue {
+			unused = append(unused, interaction.Args)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		return commandKey(unused[i]) < commandKey(unused[j])
+	})
+	return unused
+}
+
+func commandKey(tokens []string) string {
+	encoded, _ := json.Marshal(matchTokens(tokens))
+	return string(encoded)
+}
+
+// matchTokens returns the tokens compared when matching a command, with
+// the value of any TIMEOUT after the query string of a search, aggregate
+// or profile replaced by *
+func matchTokens(tokens []string) []string {
+	if len(tokens) == 0 {
+		return tokens
+	}
+
+	start := 0
+	switch strings.ToUpper(tokens[0]) {
+	case "FT.SEARCH", "FT.AGGREGATE":
+		start = 3
+	case "FT.PROFILE":
+		for n, token := range tokens {
+			if strings.EqualFold(token, "QUERY") {
+				start = n + 2
+				break
+			}
+		}
+	}
+	if start == 0 {
+		return tokens
+	}
+
+	matched := append([]string{}, tokens...)
+	for n := start; n+1 < len(matched); n++ {
+		if strings.EqualFold(matched[n], "TIMEOUT") {
+			if _, err := strconv.ParseInt(matched[n+1], 10, 64); err == nil {
+				matched[n+1] = "*"
+			}
+		}
+	}
+	return matched
+}
@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/stretchr/testify/require"
+)
+
+// liveExecutor stands in for a Redis server, replying to FT.SEARCH with a
+// canned result and failing every other command with a server error.
+type liveExecutor struct {
+	reply interface{}
+	calls int
+}
+
+func (l *liveExecutor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	l.calls++
+	if args[0] == "FT.SEARCH" {
+		return l.reply, nil
+	}
+	return nil, ServerError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "search.json")
+	live := &liveExecutor{reply: []interface{}{
+		int64(1), "doc:1", []interface{}{"title", "hello", "price", "1.5"},
+	}}
+	qry := ftsearch.NewQuery().WithIndex("idx").WithQueryString("hello")
+
+	recorder, err := New(path, Record, live)
+	require.NoError(t, err)
+	recorded, err := ftsearch.NewClientWithExecutor(recorder).Search(ctx, qry)
+	require.NoError(t, err)
+	_, err = recorder.Do(ctx, "FT.NOPE", "idx")
+	require.Error(t, err)
+	require.NoError(t, recorder.Save())
+
+	replayer, err := New(path, Replay, nil)
+	require.NoError(t, err)
+	replayed, err := ftsearch.NewClientWithExecutor(replayer).Search(ctx, qry)
+	require.NoError(t, err)
+	require.Equal(t, recorded, replayed)
+	require.Equal(t, 2, live.calls)
+
+	_, err = replayer.Do(ctx, "FT.NOPE", "idx")
+	require.EqualError(t, err, "ERR unknown command 'FT.NOPE'")
+
+	_, err = replayer.Do(ctx, "FT.SEARCH", "idx", "hello")
+	require.True(t, errors.Is(err, ErrUnrecorded))
+	require.Empty(t, replayer.Unused())
+}
+
+func TestReplayWithDeadline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.json")
+	live := &liveExecutor{reply: []interface{}{int64(1), "doc:1", []interface{}{"title", "hello"}}}
+	qry := ftsearch.NewQuery().WithIndex("idx").WithQueryString("hello")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	recorder, err := New(path, Record, live)
+	require.NoError(t, err)
+	recorded, err := ftsearch.NewClientWithExecutor(recorder).Search(ctx, qry)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	replayer, err := New(path, Replay, nil)
+	require.NoError(t, err)
+	replayed, err := ftsearch.NewClientWithExecutor(replayer).Search(ctx, qry)
+	require.NoError(t, err)
+	require.Equal(t, recorded, replayed)
+	require.Empty(t, replayer.Unused())
+
+	require.Equal(t, []string{"FT.SEARCH", "idx", "TIMEOUT", "TIMEOUT", "*", "LIMIT", "0", "5"},
+		matchTokens([]string{"FT.SEARCH", "idx", "TIMEOUT", "TIMEOUT", "5", "LIMIT", "0", "5"}))
+	require.Equal(t, []string{"FT.PROFILE", "idx", "SEARCH", "QUERY", "*", "TIMEOUT", "*"},
+		matchTokens([]string{"FT.PROFILE", "idx", "SEARCH", "QUERY", "*", "TIMEOUT", "12"}))
+}
+
+func TestReplayOrRecord(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "search.json")
+	live := &liveExecutor{reply: []interface{}{int64(0)}}
+
+	exec, err := New(path, ReplayOrRecord, live)
+	require.NoError(t, err)
+	_, err = exec.Do(ctx, "FT.SEARCH", "idx", "*", "LIMIT", int64(0), 5)
+	require.NoError(t, err)
+	require.NoError(t, exec.Save())
+
+	exec, err = New(path, ReplayOrRecord, live)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"FT.SEARCH", "idx", "*", "LIMIT", "0", "5"}}, exec.Unused())
+	_, err = exec.Do(ctx, "FT.SEARCH", "idx", "*", "LIMIT", int64(0), 5)
+	require.NoError(t, err)
+	require.Equal(t, 1, live.calls)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	reply := []interface{}{
+		"text", int64(-3), 2.5, true, nil,
+		ServerError("ERR inside"),
+		map[interface{}]interface{}{"total_results": int64(1), "results": []interface{}{}},
+	}
+
+	value, err := Encode(reply)
+	require.NoError(t, err)
+	require.Equal(t, reply, value.Decode())
+
+	_, err = Encode(struct{}{})
+	require.Error(t, err)
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "search.json")
+
+	recorder, err := New(path, Record, &liveExecutor{reply: []interface{}{int64(0)}})
+	require.NoError(t, err)
+	_, err = recorder.Do(ctx, "FT.SEARCH", "idx", "*")
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+
+	changed, err := Refresh(ctx, path, &liveExecutor{reply: []interface{}{int64(0)}}, true)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	changed, err = Refresh(ctx, path, &liveExecutor{reply: []interface{}{int64(2)}}, true)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	fixture, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), fixture.Interactions[0].Reply.Array[0].Int)
+}
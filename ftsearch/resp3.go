@@ -0,0 +1,173 @@
+package ftsearch
+
+import (
+	"fmt"
+)
+
+/******************************************************************************
+* RESP3 replies                                                               *
+******************************************************************************/
+
+// With RESP3, FT.SEARCH and FT.AGGREGATE reply with a map holding
+// total_results, results, attributes and warning rather than a flat array.
+// Each result is itself a map with the document id, score and fields in
+// extra_attributes.
+
+// replyMap converts a RESP3 map reply into a map keyed by string. go-redis
+// returns maps as map[interface{}]interface{} and rueidis as
+// map[string]interface{}. ok is false for any other reply.
+func replyMap(reply interface{}) (map[string]interface{}, bool) {
+	switch r := reply.(type) {
+	case map[string]interface{}:
+		return r, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(r))
+		for key, value := range r {
+			m[fmt.Sprint(key)] = value
+		}
+		return m, true
+	default:
+		return nil, false
+	}
+}
+
+// replyWarnings returns the warnings of a RESP3 reply, which may be sent
+// as a single string or a list of them
+func replyWarnings(reply map[string]interface{}) []string {
+	switch w := reply["warning"].(type) {
+	case string:
+		return []string{w}
+	case []interface{}:
+		warnings := make([]string, 0, len(w))
+		for _, warning := range w {
+			warnings = append(warnings, fmt.Sprint(warning))
+		}
+		return warnings
+	default:
+		return nil
+	}
+}
+
+// timeoutWarning is the warning sent when a timeout cut the results short
+const timeoutWarning = "Timeout limit was reached"
+
+// timedOut checks the warnings for the one sent when a timeout cut the
+// results short
+func timedOut(warnings []string) bool {
+	for _, warning := range warnings {
+		if warning == timeoutWarning {
+			return true
+		}
+	}
+	return false
+}
+
+// resultMaps returns the entries of the results list of a RESP3 reply
+func resultMaps(reply map[string]interface{}) ([]map[string]interface{}, error) {
+	path := memberPath("reply", "results")
+	raw, ok := reply["results"]
+	if !ok {
+		return nil, nil
+	}
+	list, err := decodeArray(path, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0, len(list))
+	for n, item := range list {
+		if result, ok := replyMap(item); ok {
+			results = append(results, result)
+		} else {
+			return nil, newReplyError(elementPath(path, n), "a map", item)
+		}
+	}
+	return results, nil
+}
+
+// totalResults returns the total_results of a RESP3 reply
+func totalResults(reply map[string]interface{}) (int64, error) {
+	return decodeInt(memberPath("reply", "total_results"), reply["total_results"])
+}
+
+// parseMap converts a RESP3 FT.SEARCH reply into QueryResults
+func (q *query) parseMap(reply map[string]interface{}) (*QueryResults, error) {
+	count, err := totalResults(reply)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := resultMaps(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	results := QueryResults{
+		Count:    count,
+		Data:     make(map[string]QueryResult, len(entries)),
+		Warnings: replyWarnings(reply),
+	}
+	results.Partial = timedOut(results.Warnings)
+
+	for n, entry := range entries {
+		path := elementPath(memberPath("reply", "results"), n)
+		result := QueryResult{}
+
+		key, err := decodeString(memberPath(path, "id"), entry["id"])
+		if err != nil {
+			return nil, err
+		}
+		if score, ok := entry["score"]; ok {
+			if result.Score, err = decodeFloat(memberPath(path, "score"), score); err != nil {
+				return nil, err
+			}
+		}
+		if fields, ok := entry["extra_attributes"]; ok {
+			if result.Value, err = decodeFields(memberPath(path, "extra_attributes"), fields); err != nil {
+				return nil, err
+			}
+			result.Snippets = q.snippets(result.Value)
+		}
+		if explanation, ok := entry["explain_score"].([]interface{}); ok {
+			result.Explanation = explanation
+		}
+		if result.Payload, err = decodePayload(memberPath(path, "payload"), entry["payload"]); err != nil {
+			return nil, err
+		}
+		results.Data[key] = result
+		results.Keys = append(results.Keys, key)
+	}
+
+	return &results, nil
+}
+
+// parseMap converts a RESP3 FT.AGGREGATE reply into AggregateResults
+func (a *aggregate) parseMap(reply map[string]interface{}) (*AggregateResults, error) {
+	count, err := totalResults(reply)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := resultMaps(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	results := AggregateResults{
+		Count:    count,
+		Rows:     make([]map[string]string, 0, len(entries)),
+		Warnings: replyWarnings(reply),
+	}
+	results.Partial = timedOut(results.Warnings)
+
+	for n, entry := range entries {
+		row := map[string]string{}
+		if fields, ok := entry["extra_attributes"]; ok {
+			path := memberPath(elementPath(memberPath("reply", "results"), n), "extra_attributes")
+			if row, err = decodeFields(path, fields); err != nil {
+				return nil, err
+			}
+		}
+		results.Rows = append(results.Rows, row)
+	}
+
+	return &results, nil
+}
@@ -0,0 +1,165 @@
+package ftsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchParseRESP3(t *testing.T) {
+	qry := NewQuery().WithIndex("idx").WithQueryString("*")
+	qry.WithScores = true
+
+	reply := map[interface{}]interface{}{
+		"attributes":    []interface{}{},
+		"format":        "STRING",
+		"total_results": int64(2),
+		"warning":       []interface{}{"Timeout limit was reached"},
+		"results": []interface{}{
+			map[interface{}]interface{}{
+				"id":               "doc:1",
+				"score":            1.5,
+				"extra_attributes": map[interface{}]interface{}{"title": "one"},
+				"values":           []interface{}{},
+			},
+		},
+	}
+
+	results, err := qry.parseReply(reply)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), results.Count)
+	require.Equal(t, []string{"Timeout limit was reached"}, results.Warnings)
+	require.True(t, results.Partial)
+	require.Equal(t, QueryResult{Score: 1.5, Value: map[string]string{"title": "one"}}, results.Data["doc:1"])
+
+	results, err = qry.parseReply([]interface{}{int64(1), "doc:1", "1.5", []interface{}{"title", "one"}})
+	require.NoError(t, err)
+	require.Equal(t, QueryResult{Score: 1.5, Value: map[string]string{"title": "one"}}, results.Data["doc:1"])
+	require.Nil(t, results.Warnings)
+	require.False(t, results.Partial)
+
+	reply["warning"] = []interface{}{"Query timeout policy is RETURN"}
+	results, err = qry.parseReply(reply)
+	require.NoError(t, err)
+	require.False(t, results.Partial)
+}
+
+func TestAggregateParseRESP3(t *testing.T) {
+	reply := map[string]interface{}{
+		"total_results": int64(1),
+		"warning":       []interface{}{},
+		"results": []interface{}{
+			map[string]interface{}{
+				"extra_attributes": map[string]interface{}{"genre": "tech", "count": "2"},
+				"values":           []interface{}{},
+			},
+		},
+	}
+
+	results, err := NewAggregate().parseReply(reply)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), results.Count)
+	require.Equal(t, []map[string]string{{"genre": "tech", "count": "2"}}, results.Rows)
+	require.Empty(t, results.Warnings)
+	require.False(t, results.Partial)
+}
+
+func TestProfileParseRESP3(t *testing.T) {
+	client, _ := newFakeClient(map[string]interface{}{
+		"FT.PROFILE": map[interface{}]interface{}{
+			"Results": map[interface{}]interface{}{
+				"total_results": int64(1),
+				"results": []interface{}{
+					map[interface{}]interface{}{"id": "doc:1", "extra_attributes": map[interface{}]interface{}{"title": "hello"}},
+				},
+			},
+			"Profile": map[interface{}]interface{}{
+				"Shards": []interface{}{
+					map[interface{}]interface{}{
+						"Total profile time": 1.5,
+						"Parsing time":       0.2,
+						"Iterators profile": map[interface{}]interface{}{
+							"Type":    "INTERSECT",
+							"Counter": int64(1),
+							"Child iterators": []interface{}{
+								map[interface{}]interface{}{"Type": "TEXT", "Term": "hello", "Size": int64(4)},
+								map[interface{}]interface{}{"Type": "TEXT", "Term": "world", "Size": int64(3)},
+							},
+						},
+						"Result processors profile": []interface{}{
+							map[interface{}]interface{}{"Type": "Index", "Time": 0.1, "Counter": int64(1)},
+							map[interface{}]interface{}{"Type": "Loader", "Time": 0.05, "Counter": int64(1)},
+						},
+					},
+				},
+				"Coordinator": map[interface{}]interface{}{},
+			},
+		},
+	})
+
+	results, err := client.Profile(context.Background(), NewQuery().WithIndex("idx").WithQueryString("hello world"), false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"doc:1"}, results.Search.Keys)
+	require.Equal(t, 1.5, results.Profile.TotalTime)
+	require.Equal(t, 0.2, results.Profile.ParsingTime)
+	require.Equal(t, "INTERSECT", results.Profile.Iterators.Type)
+	require.Len(t, results.Profile.Iterators.Children, 2)
+	require.Equal(t, "world", results.Profile.Iterators.Children[1].Term)
+	require.Equal(t, int64(3), results.Profile.Iterators.Children[1].Size)
+	require.Equal(t, []*ResultProcessorProfile{
+		{Type: "Index", Time: 0.1, Counter: 1},
+		{Type: "Loader", Time: 0.05, Counter: 1},
+	}, results.Profile.ResultProcessors)
+}
+
+func TestSpellCheckParseRESP3(t *testing.T) {
+	client, _ := newFakeClient(map[string]interface{}{
+		"FT.SPELLCHECK": map[interface{}]interface{}{
+			"results": map[interface{}]interface{}{
+				"wrld": []interface{}{},
+				"helo": []interface{}{
+					map[interface{}]interface{}{"hello": 0.6},
+					map[interface{}]interface{}{"help": 0.2},
+				},
+			},
+		},
+	})
+
+	results, err := client.SpellCheck(context.Background(), NewSpellCheck().WithIndex("idx").WithQueryString("helo wrld"))
+	require.NoError(t, err)
+	require.Equal(t, []SpellCheckResult{
+		{Term: "helo", Suggestions: []SpellCheckSuggestion{{Score: 0.6, Suggestion: "hello"}, {Score: 0.2, Suggestion: "help"}}},
+		{Term: "wrld"},
+	}, results.Results)
+}
+
+func TestSynDumpRESP3(t *testing.T) {
+	client, _ := newFakeClient(map[string]interface{}{
+		"FT.SYNDUMP": map[interface{}]interface{}{
+			"color": []interface{}{"colour"},
+			"big":   []interface{}{"size", "scale"},
+		},
+	})
+
+	dump, err := client.SynDump(context.Background(), "idx")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"color": {"colour"},
+		"big":   {"size", "scale"},
+	}, dump)
+}
+
+func TestSuggestGetRESP3(t *testing.T) {
+	get := NewSuggestGet("hel").WithScores().WithPayloads()
+	raw := []interface{}{
+		"hello", 2.5, "greeting",
+		"help", 1.0, nil,
+	}
+	suggestions, err := get.parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, []Suggestion{
+		{Term: "hello", Score: 2.5, Payload: "greeting"},
+		{Term: "help", Score: 1},
+	}, suggestions)
+}
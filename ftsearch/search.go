@@ -1,15 +1,85 @@
 // ftsearch main module - defines the client class
 package ftsearch
 
-import "github.com/go-redis/redis/v8"
+import (
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
 
 type Client struct {
-	client *redis.Client
+	exec    Executor
+	timeout time.Duration
+
+	hooksLock sync.RWMutex
+	hooks     []*indexHook
+	nextHook  uint64
+}
+
+// indexHook is a function registered with OnIndexWrite
+type indexHook struct {
+	id   uint64
+	call func(index string)
 }
 
-// NewClient returns a new search client
-func NewClient(c *redis.Client) *Client {
+// NewClient returns a new search client. Any go-redis v8 client may be
+// used - a *redis.Client, a *redis.ClusterClient, a *redis.Ring or a
+// failover client.
+//
+// Deprecated: use goredisv8.NewClient, or NewClientWithExecutor with the
+// executor of another driver.
+func NewClient(c redis.UniversalClient) *Client {
+	return NewClientWithExecutor(NewGoRedisExecutor(c))
+}
+
+// NewClientWithExecutor returns a new search client sending its commands
+// through the executor given. This allows mocks, middleware or other
+// drivers to be used.
+func NewClientWithExecutor(e Executor) *Client {
 	return &Client{
-		client: c,
+		exec: e,
+	}
+}
+
+// WithDefaultTimeout sets the TIMEOUT sent with searches which do not set
+// their own, returning the client for chaining. Whether or not a default
+// is set, a context deadline sooner than it is sent instead so that the
+// server stops work the caller will no longer wait for.
+func (c *Client) WithDefaultTimeout(timeout time.Duration) *Client {
+	c.timeout = timeout
+	return c
+}
+
+// OnIndexWrite registers a function called with the index name after the
+// client changes an index - creating or dropping it or updating its
+// synonyms. Hooks must not block. Writes to documents are not made
+// through the client so do not call them. The function returned removes
+// the hook and may be called more than once.
+func (c *Client) OnIndexWrite(hook func(index string)) func() {
+	c.hooksLock.Lock()
+	defer c.hooksLock.Unlock()
+	c.nextHook++
+	id := c.nextHook
+	c.hooks = append(c.hooks, &indexHook{id: id, call: hook})
+
+	return func() {
+		c.hooksLock.Lock()
+		defer c.hooksLock.Unlock()
+		for n, registered := range c.hooks {
+			if registered.id == id {
+				c.hooks = append(c.hooks[:n:n], c.hooks[n+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// indexWritten calls the hooks registered with OnIndexWrite
+func (c *Client) indexWritten(index string) {
+	c.hooksLock.RLock()
+	defer c.hooksLock.RUnlock()
+	for _, hook := range c.hooks {
+		hook.call(index)
 	}
 }
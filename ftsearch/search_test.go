@@ -0,0 +1,196 @@
+package ftsearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExecutor stands in for Redis, recording the commands sent and
+// replying with canned values keyed by command name.
+type fakeExecutor struct {
+	replies map[string]interface{}
+	sent    []string
+}
+
+func newFakeClient(replies map[string]interface{}) (*Client, *fakeExecutor) {
+	fake := &fakeExecutor{replies: replies}
+	return NewClientWithExecutor(fake), fake
+}
+
+func (f *fakeExecutor) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	f.sent = append(f.sent, fmt.Sprintf("%v", args))
+
+	name := strings.ToUpper(args[0].(string))
+	reply, ok := f.replies[name]
+	if !ok {
+		return nil, fmt.Errorf("ERR unknown command '%s'", name)
+	}
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func TestClientAcceptsUniversalClients(t *testing.T) {
+	require.NotNil(t, NewClient(redis.NewClient(&redis.Options{})))
+	require.NotNil(t, NewClient(redis.NewClusterClient(&redis.ClusterOptions{})))
+	require.NotNil(t, NewClient(redis.NewFailoverClient(&redis.FailoverOptions{})))
+}
+
+func TestSearchWithFake(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{
+		"FT.SEARCH": []interface{}{
+			int64(1),
+			"doc:1", "1.5", []interface{}{"title", "hello"},
+		},
+	})
+
+	qry := NewQuery().WithIndex("idx").WithQueryString("hello")
+	qry.WithScores = true
+	results, err := client.Search(context.Background(), qry)
+	require.NoError(t, err)
+	require.Equal(t, []string{"[FT.SEARCH idx hello WITHSCORES]"}, fake.sent)
+	require.Equal(t, int64(1), results.Count)
+	require.Equal(t, QueryResult{Score: 1.5, Value: map[string]string{"title": "hello"}}, results.Data["doc:1"])
+}
+
+func TestSyncSynonymsWithFake(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{
+		"FT.SYNDUMP":   []interface{}{"big", []interface{}{"size"}, "large", []interface{}{"size"}},
+		"FT.SYNUPDATE": "OK",
+	})
+
+	updated, err := client.SyncSynonyms(context.Background(), "idx", map[string][]string{
+		"size":   {"big", "large"},
+		"colour": {"color", "colour"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"colour"}, updated)
+	require.Equal(t, "[FT.SYNUPDATE idx colour color colour]", fake.sent[1])
+}
+
+func TestFirstKeyPos(t *testing.T) {
+	require.Equal(t, 1, FirstKeyPos([]interface{}{"FT.SUGADD", "sug", "hello", "1"}))
+	require.Equal(t, 1, FirstKeyPos([]interface{}{"ft.suglen", "sug"}))
+	require.Equal(t, 0, FirstKeyPos([]interface{}{"FT.SEARCH", "idx", "*"}))
+	require.Equal(t, 0, FirstKeyPos([]interface{}{"FT.SUGLEN"}))
+}
+
+func TestDoMultiWithoutBatching(t *testing.T) {
+	client, fake := newFakeClient(map[string]interface{}{
+		"FT.SUGADD": int64(1),
+	})
+
+	err := NewSuggester(client, "sug").Load(context.Background(), []*suggestion{
+		NewSuggestion("hello", 1),
+		NewSuggestion("help", 2),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"[FT.SUGADD sug hello 1]", "[FT.SUGADD sug help 2]"}, fake.sent)
+}
+
+func TestFormatArgs(t *testing.T) {
+	args := []interface{}{"FT.SEARCH", "idx", "hello", "LIMIT", int64(0), 10, "SLOP", int32(2), 1.5, []byte("raw"), true}
+	require.Equal(t,
+		[]string{"FT.SEARCH", "idx", "hello", "LIMIT", "0", "10", "SLOP", "2", "1.5", "raw", "1"},
+		FormatArgs(args))
+}
+
+func TestSearchSortBy(t *testing.T) {
+	qry := NewQuery().WithIndex("idx").WithQueryString("*").WithSortBy("year", false)
+	require.Equal(t, "[FT.SEARCH idx * SORTBY year DESC]", qry.String())
+}
+
+func TestSearchTimeout(t *testing.T) {
+	qry := NewQuery().WithIndex("idx").WithQueryString("*").WithTimeout(1500 * time.Microsecond)
+	require.Equal(t, "[FT.SEARCH idx * TIMEOUT 2]", qry.String())
+
+	reply := []interface{}{int64(0)}
+	client, fake := newFakeClient(map[string]interface{}{"FT.SEARCH": reply})
+	client.WithDefaultTimeout(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, err := client.Search(ctx, NewQuery().WithIndex("idx").WithQueryString("*"))
+	require.NoError(t, err)
+	require.Equal(t, "[FT.SEARCH idx * TIMEOUT 60000]", fake.sent[0])
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Search(ctx, NewQuery().WithIndex("idx").WithQueryString("*"))
+	require.NoError(t, err)
+	require.Regexp(t, `TIMEOUT [1-5]?[0-9]\]$`, fake.sent[1])
+
+	_, err = client.Search(ctx, qry)
+	require.NoError(t, err)
+	require.Equal(t, "[FT.SEARCH idx * TIMEOUT 2]", fake.sent[2])
+}
+
+func TestAggregateTimeout(t *testing.T) {
+	agg := NewAggregate().WithIndex("idx").WithTimeout(time.Second).AddLimit(0, 5)
+	require.Equal(t, "[FT.AGGREGATE idx * TIMEOUT 1000 LIMIT 0 5]", agg.String())
+
+	client, fake := newFakeClient(map[string]interface{}{"FT.AGGREGATE": []interface{}{int64(0)}})
+	client.WithDefaultTimeout(time.Minute)
+	ctx := context.Background()
+
+	_, err := client.Aggregate(ctx, NewAggregate().WithIndex("idx"))
+	require.NoError(t, err)
+	_, err = client.Aggregate(ctx, agg)
+	require.NoError(t, err)
+
+	batch := client.NewBatch()
+	batch.Aggregate(NewAggregate().WithIndex("idx"))
+	require.NoError(t, batch.Exec(ctx))
+
+	_, err = client.TagFacets(ctx, NewQuery().WithIndex("idx"), []string{"genre"}, "", 0)
+	require.NoError(t, err)
+	_, err = client.TagFacets(ctx, NewQuery().WithIndex("idx").WithTimeout(time.Second), []string{"genre"}, "", 0)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"[FT.AGGREGATE idx * TIMEOUT 60000]",
+		"[FT.AGGREGATE idx * TIMEOUT 1000 LIMIT 0 5]",
+		"[FT.AGGREGATE idx * TIMEOUT 60000]",
+		"[FT.AGGREGATE idx * LOAD 1 @genre APPLY split(@genre, \",\") AS value GROUPBY 1 @value REDUCE COUNT 0 AS count SORTBY 2 @count DESC TIMEOUT 60000]",
+		"[FT.AGGREGATE idx * LOAD 1 @genre TIMEOUT 1000 APPLY split(@genre, \",\") AS value GROUPBY 1 @value REDUCE COUNT 0 AS count SORTBY 2 @count DESC]",
+	}, fake.sent)
+}
+
+func TestSearchScoring(t *testing.T) {
+	qry := NewQuery().WithIndex("idx").WithQueryString("go").
+		WithScorer(ScorerBM25).WithExpander("SYNONYM").WithPayload("boost")
+	qry.WithScores = true
+	qry.WithPayloads = true
+	qry.ExplainScore = true
+	require.Equal(t, "[FT.SEARCH idx go WITHSCORES WITHPAYLOADS EXPANDER SYNONYM SCORER BM25 EXPLAINSCORE PAYLOAD boost]", qry.String())
+
+	explanation := []interface{}{"Final BM25 : words BM25 1.00 * document score 1.00", []interface{}{}}
+	results, err := qry.parseReply([]interface{}{int64(2),
+		"doc:1", []interface{}{"1.5", explanation}, "tagged", []interface{}{"title", "one"},
+		"doc:2", []interface{}{"0.5", explanation}, nil, []interface{}{"title", "two"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"doc:1", "doc:2"}, results.Keys)
+	require.Equal(t, QueryResult{Score: 1.5, Explanation: explanation, Payload: "tagged",
+		Value: map[string]string{"title": "one"}}, results.Data["doc:1"])
+	require.Equal(t, "", results.Data["doc:2"].Payload)
+
+	qry.ExplainScore = false
+	results, err = qry.parseReply([]interface{}{int64(1), "doc:1", "1.5", "tagged", []interface{}{"title", "one"}})
+	require.NoError(t, err)
+	require.Equal(t, "tagged", results.Data["doc:1"].Payload)
+
+	client, fake := newFakeClient(map[string]interface{}{"FT.SEARCH": []interface{}{int64(0)}})
+	qry = NewQuery().WithIndex("idx").WithQueryString("go")
+	qry.ExplainScore = true
+	_, err = client.Search(context.Background(), qry)
+	require.EqualError(t, err, "ftsearch: EXPLAINSCORE requires WITHSCORES")
+	require.Empty(t, fake.sent)
+}
@@ -0,0 +1,225 @@
+package ftsearch
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// SearchIterator walks every result of a query, fetching a page at a time.
+// Use it as:
+//
+//	it := client.SearchIter(ctx, qry, 100)
+//	for it.Next() {
+//		key, doc := it.Key(), it.Doc()
+//	}
+//	if err := it.Err(); err != nil {...}
+type SearchIterator struct {
+	client   *Client
+	ctx      context.Context
+	qry      *query
+	pageSize int64
+	keyset   bool
+
+	page   *QueryResults
+	pos    int
+	offset int64 // offset of the next page
+	seen   int64
+	done   bool
+	err    error
+
+	// keyset pagination state: the sort value of the last result and the
+	// keys already returned with that value
+	boundary     string
+	boundaryKeys map[string]bool
+}
+
+// SearchIter returns an iterator over all the results of qry, which is
+// not modified. Pages of pageSize results are fetched by advancing LIMIT
+// until Count results have been returned.
+func (c *Client) SearchIter(ctx context.Context, qry *query, pageSize int64) *SearchIterator {
+	if pageSize <= 0 {
+		pageSize = defaultLimit
+	}
+	return &SearchIterator{
+		client:   c,
+		ctx:      ctx,
+		qry:      qry,
+		pageSize: pageSize,
+	}
+}
+
+// WithKeyset switches to keyset pagination, returning the iterator.
+// Rather than skipping ever more results with LIMIT, each page adds a
+// FILTER starting at the sort value of the last result, so deep pages
+// cost no more than the first. The query must sort by a NUMERIC attribute
+// with WithSortBy and return it in the document fields. Results sharing
+// the boundary value are not repeated.
+func (it *SearchIterator) WithKeyset() *SearchIterator {
+	it.keyset = true
+	return it
+}
+
+// Next advances to the next result, fetching a page when needed. It
+// returns false when the results are exhausted or an error occurs.
+func (it *SearchIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.page == nil || it.pos >= len(it.page.Keys)-1 {
+		if it.done {
+			return false
+		}
+		if it.err = it.fetch(); it.err != nil {
+			return false
+		}
+		if it.pos < len(it.page.Keys)-1 {
+			break
+		}
+	}
+
+	it.pos++
+	it.seen++
+	if it.keyset {
+		if it.err = it.advanceBoundary(); it.err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Key returns the key of the current result
+func (it *SearchIterator) Key() string {
+	if it.page == nil || it.pos < 0 || it.pos >= len(it.page.Keys) {
+		return ""
+	}
+	return it.page.Keys[it.pos]
+}
+
+// Doc returns the current result
+func (it *SearchIterator) Doc() QueryResult {
+	if it.page == nil {
+		return QueryResult{}
+	}
+	return it.page.Data[it.Key()]
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// fetch runs the query for the next page
+func (it *SearchIterator) fetch() error {
+	next, err := it.pageQuery()
+	if err != nil {
+		return err
+	}
+
+	results, err := it.client.Search(it.ctx, next)
+	if err != nil {
+		return err
+	}
+
+	returned := int64(len(results.Keys))
+	if it.keyset {
+		results = it.dropBoundaryKeys(results)
+	} else {
+		it.offset += returned
+	}
+	it.page, it.pos = results, -1
+
+	switch {
+	case len(results.Keys) == 0, returned < next.Limit.Num:
+		// a short page means nothing follows it
+		it.done = true
+	case !it.keyset && it.seen+int64(len(results.Keys)) >= results.Count:
+		it.done = true
+	}
+	return nil
+}
+
+// pageQuery copies the query, limiting it to the next page
+func (it *SearchIterator) pageQuery() (*query, error) {
+	next := *it.qry
+	if !it.keyset {
+		next.Limit = NewQueryLimit(it.offset, it.pageSize)
+		return &next, nil
+	}
+
+	if next.SortBy == nil {
+		return nil, errors.New("ftsearch: keyset pagination needs a query sorted with WithSortBy")
+	}
+	if next.NoContent {
+		return nil, errors.New("ftsearch: keyset pagination needs the sort attribute returned")
+	}
+	field := next.SortBy.field()
+	if len(next.ReturnFields) > 0 && !containsString(next.ReturnFields, field) && !containsString(next.ReturnFields, "@"+field) {
+		next.ReturnFields = append(append(countedArgs{}, next.ReturnFields...), field)
+	}
+
+	// ask for the keys already returned with the boundary value as well so
+	// that a full page of new results is returned however many tie
+	next.Limit = NewQueryLimit(0, it.pageSize+int64(len(it.boundaryKeys)))
+	if it.boundaryKeys != nil {
+		boundary, err := strconv.ParseFloat(it.boundary, 64)
+		if err != nil {
+			return nil, newReplyError(it.boundaryPath(), "a number", it.boundary)
+		}
+		filter := NewQueryFilter(field)
+		if next.SortBy.Ascending {
+			filter.WithMinInclusive(boundary)
+		} else {
+			filter.WithMaxInclusive(boundary)
+		}
+		next.Filters = append(append(queryFilterList{}, next.Filters...), filter)
+	}
+	return &next, nil
+}
+
+// dropBoundaryKeys removes results already returned with the boundary value
+func (it *SearchIterator) dropBoundaryKeys(results *QueryResults) *QueryResults {
+	if len(it.boundaryKeys) == 0 {
+		return results
+	}
+
+	keys := make([]string, 0, len(results.Keys))
+	for _, key := range results.Keys {
+		if it.boundaryKeys[key] {
+			delete(results.Data, key)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	results.Keys = keys
+	return results
+}
+
+// advanceBoundary records the sort value of the current result
+func (it *SearchIterator) advanceBoundary() error {
+	value, ok := it.Doc().Value[it.qry.SortBy.field()]
+	if !ok {
+		return newReplyError(it.boundaryPath(), "the sort attribute", nil)
+	}
+
+	if it.boundaryKeys == nil || value != it.boundary {
+		it.boundary = value
+		it.boundaryKeys = make(map[string]bool)
+	}
+	it.boundaryKeys[it.Key()] = true
+	return nil
+}
+
+func (it *SearchIterator) boundaryPath() string {
+	return memberPath(memberPath("reply", it.Key()), it.qry.SortBy.field())
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
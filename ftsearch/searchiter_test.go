@@ -0,0 +1,71 @@
+package ftsearch_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/nic-gibson/go-redis-search/ftsearch/ftsearchtest"
+	"github.com/stretchr/testify/require"
+)
+
+// newScores returns a client for an index of 25 documents, score:00 to
+// score:24, whose rank values repeat every ten documents
+func newScores(t *testing.T) *ftsearch.Client {
+	client, engine := ftsearchtest.NewClient()
+	_, err := client.CreateIndex(context.Background(), ftsearch.NewCreate().WithIndex("scores").
+		WithSchema(ftsearch.NewSchema().WithIdentifier("rank").AttributeType("NUMERIC")))
+	require.NoError(t, err)
+
+	for n := 0; n < 25; n++ {
+		engine.HSet(fmt.Sprintf("score:%02d", n), map[string]string{"rank": fmt.Sprint(n % 10)})
+	}
+	return client
+}
+
+func collect(t *testing.T, it *ftsearch.SearchIterator) []string {
+	var keys []string
+	for it.Next() {
+		require.NotNil(t, it.Doc().Value)
+		keys = append(keys, it.Key())
+	}
+	require.NoError(t, it.Err())
+	return keys
+}
+
+func TestSearchIter(t *testing.T) {
+	client := newScores(t)
+	qry := ftsearch.NewQuery().WithIndex("scores").WithQueryString("*")
+
+	keys := collect(t, client.SearchIter(context.Background(), qry, 10))
+	require.Len(t, keys, 25)
+	require.Equal(t, "score:00", keys[0])
+	require.Equal(t, "score:24", keys[24])
+
+	keys = collect(t, client.SearchIter(context.Background(), qry, 5))
+	require.Len(t, keys, 25)
+}
+
+func TestSearchIterKeyset(t *testing.T) {
+	client := newScores(t)
+
+	for _, sortBy := range []struct {
+		attribute string
+		ascending bool
+	}{{"rank", true}, {"rank", false}, {"@rank", true}, {"@rank", false}} {
+		qry := ftsearch.NewQuery().WithIndex("scores").WithQueryString("*").WithSortBy(sortBy.attribute, sortBy.ascending)
+		keys := collect(t, client.SearchIter(context.Background(), qry, 4).WithKeyset())
+		require.Len(t, keys, 25)
+
+		unique := map[string]bool{}
+		for _, key := range keys {
+			unique[key] = true
+		}
+		require.Len(t, unique, 25)
+	}
+
+	it := client.SearchIter(context.Background(), ftsearch.NewQuery().WithIndex("scores"), 4).WithKeyset()
+	require.False(t, it.Next())
+	require.Error(t, it.Err())
+}
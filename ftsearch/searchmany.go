@@ -0,0 +1,249 @@
+package ftsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergedResults holds the results of a search across several indexes.
+// Count is the total of the counts from each index and Results the merged
+// page of results in order.
+type MergedResults struct {
+	Count    int64
+	Results  []MergedResult
+	Warnings []string
+	Partial  bool
+}
+
+// MergedResult is a single result from a search across several indexes,
+// holding the index it was found in
+type MergedResult struct {
+	Index string
+	Key   string
+	QueryResult
+}
+
+// SearchMany runs the queries, normally the same query against different
+// indexes, in a single batch and merges the results. The queries must
+// share the same sort and limit. Without a sort results are merged by
+// score; with one, by the sort attribute, which must be returned, either
+// directly, under a RETURN alias or within a JSON document. The limit
+// applies to the merged results, so each index is asked for enough
+// results to fill the page. The queries are not modified.
+func (c *Client) SearchMany(ctx context.Context, queries ...*query) (*MergedResults, error) {
+	if len(queries) == 0 {
+		return &MergedResults{}, nil
+	}
+
+	limit := queries[0].Limit
+	if limit == nil {
+		limit = DefaultQueryLimit()
+	}
+
+	federated := make([]*query, len(queries))
+	cmds := make([][]interface{}, len(queries))
+	for n, qry := range queries {
+		if next, err := federatedQuery(qry, queries[0], limit); err != nil {
+			return nil, err
+		} else {
+			federated[n] = next
+		}
+
+		if args, err := c.searchArgs(ctx, federated[n]); err != nil {
+			return nil, err
+		} else {
+			cmds[n] = args
+		}
+	}
+
+	merged := &MergedResults{}
+	var sorted []sortedResult
+	for n, reply := range c.doMulti(ctx, cmds) {
+		if reply.Err != nil {
+			return nil, fmt.Errorf("ftsearch: searching %s: %w", queries[n].Index, reply.Err)
+		}
+		results, err := federated[n].parseReply(reply.Val)
+		if err != nil {
+			return nil, err
+		}
+
+		merged.Count += results.Count
+		merged.Warnings = append(merged.Warnings, results.Warnings...)
+		merged.Partial = merged.Partial || results.Partial
+		for _, key := range results.Keys {
+			result := sortedResult{MergedResult: MergedResult{Index: queries[n].Index, Key: key, QueryResult: results.Data[key]}}
+			if federated[n].SortBy != nil {
+				if value, ok := federated[n].sortValue(result.Value); !ok {
+					return nil, fmt.Errorf("ftsearch: result %s from %s has no value for sort attribute %s",
+						key, queries[n].Index, federated[n].SortBy.field())
+				} else {
+					result.sortValue = value
+				}
+			}
+			sorted = append(sorted, result)
+		}
+	}
+
+	sortMerged(sorted, queries[0].SortBy)
+	for _, result := range sorted {
+		merged.Results = append(merged.Results, result.MergedResult)
+	}
+
+	first, last := limit.First, limit.First+limit.Num
+	if first > int64(len(merged.Results)) {
+		first = int64(len(merged.Results))
+	}
+	if last > int64(len(merged.Results)) {
+		last = int64(len(merged.Results))
+	}
+	merged.Results = merged.Results[first:last]
+	return merged, nil
+}
+
+// federatedQuery copies a query, asking for every result up to the end of
+// the merged page along with whatever is needed to merge them
+func federatedQuery(qry *query, lead *query, limit *queryLimit) (*query, error) {
+	if !sameSortBy(qry.SortBy, lead.SortBy) {
+		return nil, fmt.Errorf("ftsearch: query on %s sorts differently from the query on %s", qry.Index, lead.Index)
+	}
+	if qryLimit := qry.Limit; qryLimit == nil && (limit.First != defaultOffset || limit.Num != defaultLimit) ||
+		qryLimit != nil && *qryLimit != *limit {
+		return nil, fmt.Errorf("ftsearch: query on %s has a different limit from the query on %s", qry.Index, lead.Index)
+	}
+
+	next := *qry
+	next.Limit = NewQueryLimit(0, limit.First+limit.Num)
+	if next.SortBy == nil {
+		next.WithScores = true
+		return &next, nil
+	}
+
+	if next.NoContent {
+		return nil, errors.New("ftsearch: merging sorted results needs the sort attribute returned")
+	}
+	if _, ok := next.ReturnFields.returnedAs(next.SortBy.field()); len(next.ReturnFields) > 0 && !ok {
+		next.ReturnFields = append(append(countedArgs{}, next.ReturnFields...), next.SortBy.field())
+	}
+	return &next, nil
+}
+
+func sameSortBy(a *querySortBy, b *querySortBy) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sortedResult is a merged result with the value it is sorted by
+type sortedResult struct {
+	MergedResult
+	sortValue string
+}
+
+// sortMerged orders merged results by score, highest first, or by the sort
+// value. Values are compared as numbers when both are numeric. Ties keep
+// the order of the queries and of each index's results.
+func sortMerged(results []sortedResult, sortBy *querySortBy) {
+	if sortBy == nil {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		})
+		return
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].sortValue, results[j].sortValue
+		if a == b {
+			return false
+		}
+
+		less := a < b
+		if af, err := strconv.ParseFloat(a, 64); err == nil {
+			if bf, err := strconv.ParseFloat(b, 64); err == nil {
+				less = af < bf
+			}
+		}
+		if sortBy.Ascending {
+			return less
+		}
+		return !less
+	})
+}
+
+// sortValue returns the value of the sort attribute in the fields of a
+// result, looking under the name it was returned as and, for JSON
+// documents returned whole, within the document
+func (q *query) sortValue(value map[string]string) (string, bool) {
+	field := q.SortBy.field()
+	name, ok := q.ReturnFields.returnedAs(field)
+	if !ok {
+		name = field
+	}
+	if v, ok := value[name]; ok {
+		return v, true
+	}
+
+	document, ok := value["$"]
+	if !ok {
+		return "", false
+	}
+	return jsonValue(document, field)
+}
+
+// returnedAs returns the name a RETURN list returns the attribute under,
+// following any AS alias, and whether the list includes it at all
+func (c countedArgs) returnedAs(attribute string) (string, bool) {
+	for n := 0; n < len(c); n++ {
+		name := strings.TrimPrefix(c[n], "@")
+		as := name
+		if n+2 < len(c) && strings.EqualFold(c[n+1], "AS") {
+			as = c[n+2]
+			n += 2
+		}
+		if name == attribute || as == attribute {
+			return as, true
+		}
+	}
+	return "", false
+}
+
+// jsonValue finds a scalar in a JSON document, given either a JSONPath of
+// dotted names such as $.price.net or the name of a top level member
+func jsonValue(document string, path string) (string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+	var current interface{}
+	if err := decoder.Decode(&current); err != nil {
+		return "", false
+	}
+
+	names := []string{path}
+	if strings.HasPrefix(path, "$.") {
+		names = strings.Split(strings.TrimPrefix(path, "$."), ".")
+	}
+	for _, name := range names {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if current, ok = object[name]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
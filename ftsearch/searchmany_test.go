@@ -0,0 +1,111 @@
+package ftsearch_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nic-gibson/go-redis-search/ftsearch"
+	"github.com/nic-gibson/go-redis-search/ftsearch/ftsearchtest"
+	"github.com/stretchr/testify/require"
+)
+
+// newRegions returns a client with eu and us indexes over four items,
+// two in each region
+func newRegions(t *testing.T) *ftsearch.Client {
+	client, engine := ftsearchtest.NewClient()
+	for _, index := range []string{"eu", "us"} {
+		_, err := client.CreateIndex(context.Background(), ftsearch.NewCreate().WithIndex(index).
+			WithSchema(ftsearch.NewSchema().WithIdentifier("name").AttributeType("TEXT")).
+			WithSchema(ftsearch.NewSchema().WithIdentifier("region").AttributeType("TAG")).
+			WithSchema(ftsearch.NewSchema().WithIdentifier("price").AttributeType("NUMERIC")))
+		require.NoError(t, err)
+	}
+	engine.HSet("item:1", map[string]string{"name": "red shoe", "region": "eu", "price": "30"})
+	engine.HSet("item:2", map[string]string{"name": "red red shoe", "region": "us", "price": "10"})
+	engine.HSet("item:3", map[string]string{"name": "red", "region": "eu", "price": "20"})
+	engine.HSet("item:4", map[string]string{"name": "blue shoe", "region": "us", "price": "40"})
+	return client
+}
+
+func sources(results *ftsearch.MergedResults) []string {
+	var sources []string
+	for _, result := range results.Results {
+		sources = append(sources, result.Index+"/"+result.Key)
+	}
+	return sources
+}
+
+func TestSearchMany(t *testing.T) {
+	client := newRegions(t)
+	ctx := context.Background()
+
+	results, err := client.SearchMany(ctx,
+		ftsearch.NewQuery().WithIndex("eu").WithQueryString("@region:{eu} red"),
+		ftsearch.NewQuery().WithIndex("us").WithQueryString("@region:{us} red"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), results.Count)
+	require.Equal(t, []string{"us/item:2", "eu/item:1", "eu/item:3"}, sources(results))
+	require.Equal(t, "red red shoe", results.Results[0].Value["name"])
+
+	results, err = client.SearchMany(ctx,
+		ftsearch.NewQuery().WithIndex("eu").WithQueryString("@region:{eu}").WithSortBy("price", false).WithLimit(1, 2),
+		ftsearch.NewQuery().WithIndex("us").WithQueryString("@region:{us}").WithSortBy("price", false).WithLimit(1, 2),
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), results.Count)
+	require.Equal(t, []string{"eu/item:1", "eu/item:3"}, sources(results))
+
+	_, err = client.SearchMany(ctx,
+		ftsearch.NewQuery().WithIndex("eu").WithSortBy("price", false),
+		ftsearch.NewQuery().WithIndex("us").WithSortBy("price", true),
+	)
+	require.EqualError(t, err, "ftsearch: query on us sorts differently from the query on eu")
+
+	_, err = client.SearchMany(ctx,
+		ftsearch.NewQuery().WithIndex("eu").WithQueryString("*"),
+		ftsearch.NewQuery().WithIndex("missing").WithQueryString("*"),
+	)
+	require.EqualError(t, err, "ftsearch: searching missing: missing: no such index")
+}
+
+func TestSearchManySortValues(t *testing.T) {
+	client := newRegions(t)
+	ctx := context.Background()
+
+	results, err := client.SearchMany(ctx,
+		ftsearch.NewQuery().WithIndex("eu").WithQueryString("@region:{eu}").WithSortBy("@price", true).
+			WithReturnFields([]string{"name", "price", "AS", "cost"}),
+		ftsearch.NewQuery().WithIndex("us").WithQueryString("@region:{us}").WithSortBy("@price", true).
+			WithReturnFields([]string{"name", "price", "AS", "cost"}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"us/item:2", "eu/item:3", "eu/item:1", "us/item:4"}, sources(results))
+	require.Equal(t, "10", results.Results[0].Value["cost"])
+
+	client, engine := ftsearchtest.NewClient()
+	for _, index := range []string{"eu", "us"} {
+		_, err := client.CreateIndex(ctx, ftsearch.NewCreate().WithIndex(index).OnJSON().
+			WithSchema(ftsearch.NewSchema().WithIdentifier("$.name").AsAttribute("name").AttributeType("TEXT")).
+			WithSchema(ftsearch.NewSchema().WithIdentifier("$.region").AsAttribute("region").AttributeType("TAG")).
+			WithSchema(ftsearch.NewSchema().WithIdentifier("$.price").AsAttribute("price").AttributeType("NUMERIC")))
+		require.NoError(t, err)
+	}
+	require.NoError(t, engine.JSONSet("item:1", `{"name":"red shoe","region":"eu","price":30}`))
+	require.NoError(t, engine.JSONSet("item:2", `{"name":"blue shoe","region":"eu","price":5}`))
+	require.NoError(t, engine.JSONSet("item:3", `{"name":"green shoe","region":"us","price":12.5}`))
+
+	results, err = client.SearchMany(ctx,
+		ftsearch.NewQuery().WithIndex("eu").WithQueryString("@region:{eu} shoe").WithSortBy("price", false),
+		ftsearch.NewQuery().WithIndex("us").WithQueryString("@region:{us} shoe").WithSortBy("price", false),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"eu/item:1", "us/item:3", "eu/item:2"}, sources(results))
+
+	require.NoError(t, engine.JSONSet("item:4", `{"name":"odd shoe","region":"us"}`))
+	_, err = client.SearchMany(ctx,
+		ftsearch.NewQuery().WithIndex("eu").WithQueryString("@region:{eu} shoe").WithSortBy("price", false),
+		ftsearch.NewQuery().WithIndex("us").WithQueryString("@region:{us} shoe").WithSortBy("price", false),
+	)
+	require.EqualError(t, err, "ftsearch: result item:4 from us has no value for sort attribute price")
+}
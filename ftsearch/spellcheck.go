@@ -0,0 +1,271 @@
+// spellcheck provides an interface to RedisSearch's spelling correction and
+// custom dictionary functionality.
+package ftsearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type spellCheck struct {
+	Index       string
+	QueryString string
+	Distance    int32
+	Terms       []*spellCheckTerms
+	Dialect     int32
+}
+
+// spellCheckTerms is a single TERMS INCLUDE or TERMS EXCLUDE clause
+type spellCheckTerms struct {
+	Mode       string
+	Dictionary string
+	Terms      []string
+}
+
+// SpellCheckSuggestion is a single suggested correction for a term
+type SpellCheckSuggestion struct {
+	Score      float64
+	Suggestion string
+}
+
+// SpellCheckResult holds the suggestions for one misspelled term
+type SpellCheckResult struct {
+	Term        string
+	Suggestions []SpellCheckSuggestion
+}
+
+type SpellCheckResults struct {
+	Results []SpellCheckResult
+}
+
+const (
+	noDistance       = 0 // no distance set, the server default (1) is used
+	noDialect        = 0 // no dialect set, the server default is used
+	spellCheckInc    = "INCLUDE"
+	spellCheckExc    = "EXCLUDE"
+	spellCheckResult = "TERM"
+)
+
+// NewSpellCheck creates a new spellcheck with defaults set
+// https://redis.io/commands/ft.spellcheck/
+func NewSpellCheck() *spellCheck {
+	return &spellCheck{
+		Distance: noDistance,
+		Dialect:  noDialect,
+	}
+}
+
+// String returns the serialized spellcheck as a single string. Any quoting
+// required to use it in redis-cli is not done.
+func (s *spellCheck) String() string {
+	return fmt.Sprintf("%v", s.serialize())
+}
+
+// WithIndex sets the index to be checked against, returning the
+// updated spellcheck for chaining
+func (s *spellCheck) WithIndex(index string) *spellCheck {
+	s.Index = index
+	return s
+}
+
+// WithQueryString sets the query to be checked, returning the
+// updated spellcheck for chaining
+func (s *spellCheck) WithQueryString(queryString string) *spellCheck {
+	s.QueryString = queryString
+	return s
+}
+
+// WithDistance sets the maximum Levenshtein distance for suggestions (1 to 4),
+// returning the updated spellcheck for chaining
+func (s *spellCheck) WithDistance(distance int32) *spellCheck {
+	s.Distance = distance
+	return s
+}
+
+// WithDialect sets the query dialect, returning the updated spellcheck
+// for chaining
+func (s *spellCheck) WithDialect(dialect int32) *spellCheck {
+	s.Dialect = dialect
+	return s
+}
+
+// AddInclude adds a dictionary whose terms are used as suggestions,
+// returning the updated spellcheck for chaining
+func (s *spellCheck) AddInclude(dictionary string, terms ...string) *spellCheck {
+	s.Terms = append(s.Terms, &spellCheckTerms{Mode: spellCheckInc, Dictionary: dictionary, Terms: terms})
+	return s
+}
+
+// AddExclude adds a dictionary whose terms are never suggested,
+// returning the updated spellcheck for chaining
+func (s *spellCheck) AddExclude(dictionary string, terms ...string) *spellCheck {
+	s.Terms = append(s.Terms, &spellCheckTerms{Mode: spellCheckExc, Dictionary: dictionary, Terms: terms})
+	return s
+}
+
+// serialize converts a spellcheck struct to a slice of interface{}
+// ready for execution against Redis
+func (s *spellCheck) serialize() []interface{} {
+	var args = []interface{}{"FT.SPELLCHECK", s.Index, s.QueryString}
+
+	if s.Distance != noDistance {
+		args = append(args, "DISTANCE", s.Distance)
+	}
+
+	for _, terms := range s.Terms {
+		args = append(args, "TERMS", terms.Mode, terms.Dictionary)
+		for _, term := range terms.Terms {
+			args = append(args, term)
+		}
+	}
+
+	if s.Dialect != noDialect {
+		args = append(args, "DIALECT", s.Dialect)
+	}
+
+	return args
+}
+
+// parse converts the raw results of an FT.SPELLCHECK into SpellCheckResults
+func (s *spellCheck) parse(rawResults []interface{}) (*SpellCheckResults, error) {
+	results := SpellCheckResults{
+		Results: make([]SpellCheckResult, 0, len(rawResults)),
+	}
+
+	for n, raw := range rawResults {
+		path := elementPath("reply", n)
+		entry, err := decodeArray(path, raw)
+		if err != nil {
+			return nil, err
+		}
+		if len(entry) < 3 {
+			return nil, newReplyError(elementPath(path, len(entry)), "a term entry", nil)
+		}
+		if entry[0] != spellCheckResult {
+			return nil, newReplyError(elementPath(path, 0), spellCheckResult, entry[0])
+		}
+
+		term, err := decodeString(elementPath(path, 1), entry[1])
+		if err != nil {
+			return nil, err
+		}
+
+		result := SpellCheckResult{Term: term}
+		suggestions, err := decodeArray(elementPath(path, 2), entry[2])
+		if err != nil {
+			return nil, err
+		}
+		for m, rawSuggestion := range suggestions {
+			suggestionPath := elementPath(elementPath(path, 2), m)
+			suggestion, err := decodeArray(suggestionPath, rawSuggestion)
+			if err != nil {
+				return nil, err
+			}
+			if len(suggestion) < 2 {
+				return nil, newReplyError(elementPath(suggestionPath, len(suggestion)), "a suggestion", nil)
+			}
+
+			score, err := decodeFloat(elementPath(suggestionPath, 0), suggestion[0])
+			if err != nil {
+				return nil, err
+			}
+			text, err := decodeString(elementPath(suggestionPath, 1), suggestion[1])
+			if err != nil {
+				return nil, err
+			}
+			result.Suggestions = append(result.Suggestions, SpellCheckSuggestion{
+				Score:      score,
+				Suggestion: text,
+			})
+		}
+		results.Results = append(results.Results, result)
+	}
+
+	return &results, nil
+}
+
+// parseMap converts a RESP3 FT.SPELLCHECK reply, which maps each term to
+// a list of single entry maps from suggestion to score. Results are sorted
+// by term as the map does not keep the order of the query.
+func (s *spellCheck) parseMap(reply map[string]interface{}) (*SpellCheckResults, error) {
+	path := memberPath("reply", "results")
+	terms, ok := replyMap(reply["results"])
+	if !ok {
+		return nil, newReplyError(path, "a map", reply["results"])
+	}
+
+	results := SpellCheckResults{
+		Results: make([]SpellCheckResult, 0, len(terms)),
+	}
+	for term, raw := range terms {
+		termPath := memberPath(path, term)
+		suggestions, err := decodeArray(termPath, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		result := SpellCheckResult{Term: term}
+		for n, rawSuggestion := range suggestions {
+			suggestion, ok := replyMap(rawSuggestion)
+			if !ok {
+				return nil, newReplyError(elementPath(termPath, n), "a map", rawSuggestion)
+			}
+			for text, rawScore := range suggestion {
+				score, err := decodeFloat(memberPath(elementPath(termPath, n), text), rawScore)
+				if err != nil {
+					return nil, err
+				}
+				result.Suggestions = append(result.Suggestions, SpellCheckSuggestion{
+					Score:      score,
+					Suggestion: text,
+				})
+			}
+		}
+		results.Results = append(results.Results, result)
+	}
+
+	sort.Slice(results.Results, func(i, j int) bool {
+		return results.Results[i].Term < results.Results[j].Term
+	})
+	return &results, nil
+}
+
+// SpellCheck runs the spellcheck given, returning the suggestions for each
+// misspelled term.
+func (c *Client) SpellCheck(ctx context.Context, qry *spellCheck) (*SpellCheckResults, error) {
+	serialized := qry.serialize()
+	if reply, err := c.do(ctx, serialized); err != nil {
+		return nil, err
+	} else if m, ok := replyMap(reply); ok {
+		return qry.parseMap(m)
+	} else if rawResults, err := replySlice(reply); err != nil {
+		return nil, err
+	} else {
+		return qry.parse(rawResults)
+	}
+}
+
+// DictAdd adds terms to a dictionary, returning the number of new terms added
+func (c *Client) DictAdd(ctx context.Context, dictionary string, terms ...string) (int64, error) {
+	return c.dictUpdate(ctx, "FT.DICTADD", dictionary, terms)
+}
+
+// DictDel removes terms from a dictionary, returning the number of terms removed
+func (c *Client) DictDel(ctx context.Context, dictionary string, terms ...string) (int64, error) {
+	return c.dictUpdate(ctx, "FT.DICTDEL", dictionary, terms)
+}
+
+// DictDump returns all the terms in a dictionary
+func (c *Client) DictDump(ctx context.Context, dictionary string) ([]string, error) {
+	return c.doStrings(ctx, []interface{}{"FT.DICTDUMP", dictionary})
+}
+
+func (c *Client) dictUpdate(ctx context.Context, command string, dictionary string, terms []string) (int64, error) {
+	args := []interface{}{command, dictionary}
+	for _, term := range terms {
+		args = append(args, term)
+	}
+
+	return c.doInt(ctx, args)
+}
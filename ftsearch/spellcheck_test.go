@@ -0,0 +1,47 @@
+package ftsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpellCheck(t *testing.T) {
+	const (
+		expected = `[FT.SPELLCHECK test helo wrld DISTANCE 2 TERMS INCLUDE custom TERMS EXCLUDE badwords DIALECT 2]`
+	)
+	spellCheck := NewSpellCheck().WithIndex("test").WithQueryString("helo wrld").
+		WithDistance(2).
+		AddInclude("custom").
+		AddExclude("badwords").
+		WithDialect(2)
+
+	require.Equal(t, expected, spellCheck.String())
+}
+
+func TestSpellCheckParse(t *testing.T) {
+	raw := []interface{}{
+		[]interface{}{"TERM", "helo", []interface{}{
+			[]interface{}{"0.6", "hello"},
+			[]interface{}{"0.2", "help"},
+		}},
+		[]interface{}{"TERM", "wrld", []interface{}{}},
+	}
+
+	results, err := NewSpellCheck().parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, []SpellCheckResult{
+		{Term: "helo", Suggestions: []SpellCheckSuggestion{{Score: 0.6, Suggestion: "hello"}, {Score: 0.2, Suggestion: "help"}}},
+		{Term: "wrld"},
+	}, results.Results)
+
+	_, err = NewSpellCheck().parse([]interface{}{
+		[]interface{}{"TERM", "helo", []interface{}{"hello"}},
+	})
+	require.EqualError(t, err, "ftsearch: expected an array at reply[0][2][0], got string")
+
+	_, err = NewSpellCheck().parse([]interface{}{
+		[]interface{}{"TERM", "helo"},
+	})
+	require.EqualError(t, err, "ftsearch: expected a term entry at reply[0][2], got nil")
+}
@@ -0,0 +1,245 @@
+// suggest provides an interface to RedisSearch's autocomplete suggestion
+// dictionaries.
+package ftsearch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Suggester adds to and queries a single suggestion dictionary
+type Suggester struct {
+	client *Client
+	Key    string
+}
+
+type suggestion struct {
+	Term    string
+	Score   float64
+	Incr    bool
+	Payload string
+}
+
+type suggestGet struct {
+	Prefix   string
+	Fuzzy    bool
+	Scores   bool
+	Payloads bool
+	Max      int64
+}
+
+// Suggestion is a single result returned by Get. Score and Payload are
+// only set if requested.
+type Suggestion struct {
+	Term    string
+	Score   float64
+	Payload string
+}
+
+const (
+	noMax = 0 // no maximum set, the server default (5) is used
+)
+
+// NewSuggester returns a suggester for the dictionary stored at key
+func NewSuggester(c *Client, key string) *Suggester {
+	return &Suggester{
+		client: c,
+		Key:    key,
+	}
+}
+
+// NewSuggestion creates a new suggestion to be added with the score given
+// https://redis.io/commands/ft.sugadd/
+func NewSuggestion(term string, score float64) *suggestion {
+	return &suggestion{
+		Term:  term,
+		Score: score,
+	}
+}
+
+// WithIncr causes the score to be added to any existing score rather than
+// replacing it, returning the updated suggestion for chaining
+func (s *suggestion) WithIncr() *suggestion {
+	s.Incr = true
+	return s
+}
+
+// WithPayload sets the payload saved with the suggestion, returning the
+// updated suggestion for chaining
+func (s *suggestion) WithPayload(payload string) *suggestion {
+	s.Payload = payload
+	return s
+}
+
+// NewSuggestGet creates a new suggestion lookup for the prefix given
+// https://redis.io/commands/ft.sugget/
+func NewSuggestGet(prefix string) *suggestGet {
+	return &suggestGet{
+		Prefix: prefix,
+		Max:    noMax,
+	}
+}
+
+// WithFuzzy enables fuzzy prefix matching, returning the updated lookup
+func (g *suggestGet) WithFuzzy() *suggestGet {
+	g.Fuzzy = true
+	return g
+}
+
+// WithMax sets the maximum number of suggestions returned, returning the
+// updated lookup
+func (g *suggestGet) WithMax(max int64) *suggestGet {
+	g.Max = max
+	return g
+}
+
+// WithScores requests the score of each suggestion, returning the updated lookup
+func (g *suggestGet) WithScores() *suggestGet {
+	g.Scores = true
+	return g
+}
+
+// WithPayloads requests the payload of each suggestion, returning the
+// updated lookup
+func (g *suggestGet) WithPayloads() *suggestGet {
+	g.Payloads = true
+	return g
+}
+
+// Add adds a suggestion to the dictionary, returning the size of the dictionary
+func (s *Suggester) Add(ctx context.Context, sug *suggestion) (int64, error) {
+	return s.client.doInt(ctx, sug.serialize(s.Key))
+}
+
+// Load adds all of the suggestions to the dictionary in a single pipeline
+func (s *Suggester) Load(ctx context.Context, sugs []*suggestion) error {
+	cmds := make([][]interface{}, len(sugs))
+	for n, sug := range sugs {
+		cmds[n] = sug.serialize(s.Key)
+	}
+	for _, reply := range s.client.doMulti(ctx, cmds) {
+		if reply.Err != nil {
+			return reply.Err
+		}
+	}
+	return nil
+}
+
+// Get returns the suggestions matching the lookup
+func (s *Suggester) Get(ctx context.Context, get *suggestGet) ([]Suggestion, error) {
+	if rawResults, err := s.client.doSlice(ctx, get.serialize(s.Key)); err != nil {
+		return nil, err
+	} else {
+		return get.parse(rawResults)
+	}
+}
+
+// Del deletes a suggestion from the dictionary, returning false if it was
+// not found
+func (s *Suggester) Del(ctx context.Context, term string) (bool, error) {
+	if deleted, err := s.client.doInt(ctx, []interface{}{"FT.SUGDEL", s.Key, term}); err != nil {
+		return false, err
+	} else {
+		return deleted == 1, nil
+	}
+}
+
+// Len returns the number of suggestions in the dictionary
+func (s *Suggester) Len(ctx context.Context) (int64, error) {
+	return s.client.doInt(ctx, []interface{}{"FT.SUGLEN", s.Key})
+}
+
+// String returns the serialized suggestion as a single string. The key
+// is left empty.
+func (s *suggestion) String() string {
+	return fmt.Sprintf("%v", s.serialize(""))
+}
+
+func (s *suggestion) serialize(key string) []interface{} {
+	args := []interface{}{"FT.SUGADD", key, s.Term, strconv.FormatFloat(s.Score, 'g', -1, 64)}
+	if s.Incr {
+		args = append(args, "INCR")
+	}
+	if s.Payload != "" {
+		args = append(args, "PAYLOAD", s.Payload)
+	}
+	return args
+}
+
+// String returns the serialized lookup as a single string. The key
+// is left empty.
+func (g *suggestGet) String() string {
+	return fmt.Sprintf("%v", g.serialize(""))
+}
+
+func (g *suggestGet) serialize(key string) []interface{} {
+	args := []interface{}{"FT.SUGGET", key, g.Prefix}
+	if g.Fuzzy {
+		args = append(args, "FUZZY")
+	}
+	if g.Scores {
+		args = append(args, "WITHSCORES")
+	}
+	if g.Payloads {
+		args = append(args, "WITHPAYLOADS")
+	}
+	if g.Max != noMax {
+		args = append(args, "MAX", g.Max)
+	}
+	return args
+}
+
+// resultSize works out how many entries in the raw results are
+// used per suggestion
+func (g *suggestGet) resultSize() int {
+	count := 1
+	if g.Scores {
+		count++
+	}
+	if g.Payloads {
+		count++
+	}
+	return count
+}
+
+// parse converts the raw results of an FT.SUGGET into suggestions. Payloads
+// are nil when a suggestion was added without one.
+func (g *suggestGet) parse(rawResults []interface{}) ([]Suggestion, error) {
+	resultSize := g.resultSize()
+	if len(rawResults)%resultSize != 0 {
+		return nil, newReplyError(elementPath("reply", len(rawResults)), "a complete suggestion", nil)
+	}
+	results := make([]Suggestion, 0, len(rawResults)/resultSize)
+
+	for i := 0; i < len(rawResults); i += resultSize {
+		j := i
+		result := Suggestion{}
+		if term, err := decodeString(elementPath("reply", j), rawResults[j]); err != nil {
+			return nil, err
+		} else {
+			result.Term = term
+		}
+		j++
+
+		if g.Scores {
+			if score, err := decodeFloat(elementPath("reply", j), rawResults[j]); err != nil {
+				return nil, err
+			} else {
+				result.Score = score
+			}
+			j++
+		}
+
+		if g.Payloads && rawResults[j] != nil {
+			if payload, err := decodeString(elementPath("reply", j), rawResults[j]); err != nil {
+				return nil, err
+			} else {
+				result.Payload = payload
+			}
+		}
+
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,40 @@
+package ftsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestionAdd(t *testing.T) {
+	const (
+		expected = `[FT.SUGADD  hello world 1.5 INCR PAYLOAD greeting]`
+	)
+	sug := NewSuggestion("hello world", 1.5).WithIncr().WithPayload("greeting")
+	require.Equal(t, expected, sug.String())
+}
+
+func TestSuggestGet(t *testing.T) {
+	const (
+		expected = `[FT.SUGGET  hel FUZZY WITHSCORES WITHPAYLOADS MAX 3]`
+	)
+	get := NewSuggestGet("hel").WithFuzzy().WithMax(3).WithScores().WithPayloads()
+	require.Equal(t, expected, get.String())
+
+	raw := []interface{}{
+		"hello", "2.5", "greeting",
+		"help", "1", nil,
+	}
+	suggestions, err := get.parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, []Suggestion{
+		{Term: "hello", Score: 2.5, Payload: "greeting"},
+		{Term: "help", Score: 1},
+	}, suggestions)
+
+	_, err = get.parse([]interface{}{"hello", "high", nil})
+	require.EqualError(t, err, "ftsearch: expected a number at reply[1], got string")
+
+	_, err = get.parse([]interface{}{"hello", "2.5"})
+	require.EqualError(t, err, "ftsearch: expected a complete suggestion at reply[2], got nil")
+}
@@ -0,0 +1,187 @@
+// synonyms provides an interface to RedisSearch's synonym group functionality.
+package ftsearch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SynUpdate adds terms to the synonym group given, creating the group if
+// needed. If skipInitialScan is set documents already indexed are not
+// reindexed.
+func (c *Client) SynUpdate(ctx context.Context, index string, groupID string, terms []string, skipInitialScan bool) error {
+	args := []interface{}{"FT.SYNUPDATE", index, groupID}
+	if skipInitialScan {
+		args = append(args, "SKIPINITIALSCAN")
+	}
+	for _, term := range terms {
+		args = append(args, term)
+	}
+
+	if _, err := c.do(ctx, args); err != nil {
+		return err
+	}
+	c.indexWritten(index)
+	return nil
+}
+
+// SynDump returns the synonyms defined on an index, mapping each term to
+// the groups it belongs to.
+func (c *Client) SynDump(ctx context.Context, index string) (map[string][]string, error) {
+	if reply, err := c.do(ctx, []interface{}{"FT.SYNDUMP", index}); err != nil {
+		return nil, err
+	} else if m, ok := replyMap(reply); ok {
+		return parseSynDumpMap(m)
+	} else if rawResults, err := replySlice(reply); err != nil {
+		return nil, err
+	} else {
+		return parseSynDump(rawResults)
+	}
+}
+
+// SyncSynonyms updates the synonym groups on an index so that every group
+// in desired (group id to terms) contains all of its terms. Only groups
+// which are missing terms are updated and their ids are returned. RediSearch
+// cannot remove synonyms so extra terms on the index are left in place.
+func (c *Client) SyncSynonyms(ctx context.Context, index string, desired map[string][]string) ([]string, error) {
+	current, err := c.SynDump(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := synonymGroups(current)
+
+	var updated []string
+	for _, groupID := range sortedKeys(desired) {
+		terms := desired[groupID]
+		if containsAll(groups[groupID], terms) {
+			continue
+		}
+		if err := c.SynUpdate(ctx, index, groupID, terms, false); err != nil {
+			return updated, err
+		}
+		updated = append(updated, groupID)
+	}
+
+	return updated, nil
+}
+
+// ReadSynonyms reads synonym groups from r. Each line holds a group id
+// followed by a colon and a comma separated list of terms:
+//
+//	colour: color, colour, hue
+//
+// Blank lines and lines starting with # are ignored.
+func ReadSynonyms(r io.Reader) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		groupID, terms, found := strings.Cut(text, ":")
+		groupID = strings.TrimSpace(groupID)
+		if !found || groupID == "" {
+			return nil, fmt.Errorf("synonyms line %d: expected 'group: term, term...'", line)
+		}
+
+		for _, term := range strings.Split(terms, ",") {
+			if term = strings.TrimSpace(term); term != "" {
+				groups[groupID] = append(groups[groupID], term)
+			}
+		}
+	}
+	return groups, scanner.Err()
+}
+
+// parseSynDump converts the raw FT.SYNDUMP reply of alternating terms and
+// group lists into a map
+func parseSynDump(rawResults []interface{}) (map[string][]string, error) {
+	if len(rawResults)%2 != 0 {
+		return nil, newReplyError(elementPath("reply", len(rawResults)), "an array", nil)
+	}
+	results := make(map[string][]string, len(rawResults)/2)
+	for i := 0; i < len(rawResults); i += 2 {
+		term, err := decodeString(elementPath("reply", i), rawResults[i])
+		if err != nil {
+			return nil, err
+		}
+		if results[term], err = synonymGroupIDs(elementPath("reply", i+1), rawResults[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// parseSynDumpMap converts the RESP3 FT.SYNDUMP reply, a map from terms
+// to their group ids
+func parseSynDumpMap(reply map[string]interface{}) (map[string][]string, error) {
+	results := make(map[string][]string, len(reply))
+	for term, rawGroups := range reply {
+		if groups, err := synonymGroupIDs(memberPath("reply", term), rawGroups); err != nil {
+			return nil, err
+		} else {
+			results[term] = groups
+		}
+	}
+	return results, nil
+}
+
+func synonymGroupIDs(path string, raw interface{}) ([]string, error) {
+	rawGroups, err := decodeArray(path, raw)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(rawGroups))
+	for n, group := range rawGroups {
+		if groupID, err := decodeString(elementPath(path, n), group); err != nil {
+			return nil, err
+		} else {
+			groups = append(groups, groupID)
+		}
+	}
+	return groups, nil
+}
+
+// synonymGroups inverts a SynDump result to map group ids to their terms
+func synonymGroups(dump map[string][]string) map[string][]string {
+	groups := make(map[string][]string)
+	for term, groupIDs := range dump {
+		for _, groupID := range groupIDs {
+			groups[groupID] = append(groups[groupID], term)
+		}
+	}
+	return groups
+}
+
+// containsAll checks that have contains every entry in want. Synonym
+// terms are stored lower case so the comparison ignores case.
+func containsAll(have []string, want []string) bool {
+	set := make(map[string]bool, len(have))
+	for _, term := range have {
+		set[strings.ToLower(term)] = true
+	}
+	for _, term := range want {
+		if !set[strings.ToLower(term)] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
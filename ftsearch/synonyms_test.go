@@ -0,0 +1,50 @@
+package ftsearch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSynonyms(t *testing.T) {
+	input := `
+# colours
+colour: color, colour,hue
+
+size: big, large
+`
+	groups, err := ReadSynonyms(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"colour": {"color", "colour", "hue"},
+		"size":   {"big", "large"},
+	}, groups)
+
+	_, err = ReadSynonyms(strings.NewReader("no group here"))
+	require.Error(t, err)
+}
+
+func TestParseSynDump(t *testing.T) {
+	raw := []interface{}{
+		"color", []interface{}{"colour"},
+		"big", []interface{}{"size", "scale"},
+	}
+
+	dump, err := parseSynDump(raw)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"color": {"colour"},
+		"big":   {"size", "scale"},
+	}, dump)
+
+	groups := synonymGroups(dump)
+	require.True(t, containsAll(groups["size"], []string{"BIG"}))
+	require.False(t, containsAll(groups["size"], []string{"big", "large"}))
+
+	_, err = parseSynDump([]interface{}{"color", []interface{}{int64(1)}})
+	require.EqualError(t, err, "ftsearch: expected a string at reply[1][0], got int64")
+
+	_, err = parseSynDump([]interface{}{"color"})
+	require.EqualError(t, err, "ftsearch: expected an array at reply[1], got nil")
+}
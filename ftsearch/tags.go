@@ -0,0 +1,164 @@
+// tags provides helpers for listing TAG field values and building facets
+// from them.
+package ftsearch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FacetValue is a single tag value and the number of matching documents
+// holding it
+type FacetValue struct {
+	Value string
+	Count int64
+}
+
+const (
+	facetValue          = "value"
+	facetCount          = "count"
+	defaultTagSeparator = ","
+)
+
+// TagVals returns the distinct values of a TAG field in an index
+func (c *Client) TagVals(ctx context.Context, index string, field string) ([]string, error) {
+	return c.doStrings(ctx, []interface{}{"FT.TAGVALS", index, field})
+}
+
+// TagFacets counts the values of each of the tag fields given across the
+// documents matched by the query string and filters of qry. Values are
+// split on separator, which should match the SEPARATOR of the fields and
+// defaults to a comma when empty. At most max values, most frequent first,
+// are returned for each field; zero returns them all. One aggregate per
+// field is sent in a single pipeline. Queries using options FT.AGGREGATE
+// does not support, such as INKEYS or LANGUAGE, are rejected.
+func (c *Client) TagFacets(ctx context.Context, qry *query, fields []string, separator string, max int64) (map[string][]FacetValue, error) {
+	aggregates := make([]*aggregate, len(fields))
+	cmds := make([][]interface{}, len(fields))
+	for n, field := range fields {
+		if agg, err := facetAggregate(qry, field, separator, max); err != nil {
+			return nil, err
+		} else {
+			aggregates[n] = agg
+		}
+		if args, err := c.aggregateArgs(ctx, aggregates[n]); err != nil {
+			return nil, err
+		} else {
+			cmds[n] = args
+		}
+	}
+
+	replies := c.doMulti(ctx, cmds)
+
+	facets := make(map[string][]FacetValue, len(fields))
+	for n, field := range fields {
+		if replies[n].Err != nil {
+			return nil, replies[n].Err
+		}
+		results, err := aggregates[n].parseReply(replies[n].Val)
+		if err != nil {
+			return nil, err
+		}
+		rows := results.Rows
+		values := make([]FacetValue, 0, len(rows))
+		for _, row := range rows {
+			count, _ := strconv.ParseInt(row[facetCount], 10, 64)
+			values = append(values, FacetValue{Value: row[facetValue], Count: count})
+		}
+		facets[field] = values
+	}
+
+	return facets, nil
+}
+
+// facetAggregate builds the aggregate counting the values of one tag field.
+// Tag values are split so that documents with several values are counted
+// under each of them. The query's filters are written into the query
+// string and its VERBATIM, TIMEOUT, PARAMS and DIALECT are kept.
+func facetAggregate(qry *query, field string, separator string, max int64) (*aggregate, error) {
+	if err := qry.validate(); err != nil {
+		return nil, err
+	}
+	if option := qry.unaggregatable(); option != "" {
+		return nil, fmt.Errorf("ftsearch: tag facets do not support %s", option)
+	}
+	if separator == "" {
+		separator = defaultTagSeparator
+	}
+	if len(separator) != 1 || separator == `"` || separator == `\` {
+		return nil, fmt.Errorf("ftsearch: tag separator must be a single character other than a quote or backslash, got %q", separator)
+	}
+
+	property := "@" + strings.TrimPrefix(field, "@")
+	queryString, params := qry.withClauses(qry.filteredQueryString())
+	if len(qry.GeoFilters) > 0 {
+		clauses := []string{queryString}
+		for _, gf := range qry.GeoFilters {
+			clauses = append(clauses, GeoQuery(gf.Attribute, gf.Lon, gf.Lat, gf.Radius, gf.Unit))
+		}
+		queryString = "(" + strings.Join(clauses, ") (") + ")"
+	}
+
+	agg := NewAggregate().
+		WithIndex(qry.Index).
+		WithQueryString(queryString).
+		WithLoad([]string{property}).
+		AddApply(fmt.Sprintf(`split(%s, "%s")`, property, separator), facetValue).
+		AddGroupBy([]string{"@" + facetValue}, NewReducer("COUNT").As(facetCount))
+
+	agg.Verbatim = qry.Verbatim
+	agg.Timeout = qry.Timeout
+	agg.Params = params
+	agg.Dialect = qry.dialect()
+	agg.AddSortBy([]string{"@" + facetCount, "DESC"}, max)
+	if max > 0 {
+		agg.AddLimit(0, max)
+	}
+
+	return agg, nil
+}
+
+// unaggregatable returns the first option set on the query which changes
+// the documents matched but cannot be passed to FT.AGGREGATE, or ""
+func (q *query) unaggregatable() string {
+	switch {
+	case len(q.InKeys) > 0:
+		return "INKEYS"
+	case len(q.InFields) > 0:
+		return "INFIELDS"
+	case q.Language != "":
+		return "LANGUAGE"
+	case q.Slop != noSlop:
+		return "SLOP"
+	case q.InOrder:
+		return "INORDER"
+	case q.NoStopWords:
+		return "NOSTOPWORDS"
+	case q.Expander != "":
+		return "EXPANDER"
+	default:
+		return ""
+	}
+}
+
+// filteredQueryString returns the query string with the numeric filters
+// rewritten into query syntax, for use where FILTER is not accepted
+func (q *query) filteredQueryString() string {
+	clauses := []string{}
+	if q.QueryString != "" && q.QueryString != "*" {
+		clauses = append(clauses, q.QueryString)
+	}
+
+	for _, filter := range q.Filters {
+		clauses = append(clauses, filter.QueryString())
+	}
+
+	if len(clauses) == 0 {
+		return "*"
+	} else if len(clauses) == 1 {
+		return clauses[0]
+	}
+	return "(" + strings.Join(clauses, ") (") + ")"
+}
@@ -0,0 +1,44 @@
+package ftsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFacetAggregate(t *testing.T) {
+	const (
+		expected = `[FT.AGGREGATE test (shoes) (@price:[-inf (100]) LOAD 1 @colour APPLY split(@colour, ",") AS value GROUPBY 1 @value REDUCE COUNT 0 AS count SORTBY 2 @count DESC MAX 5 LIMIT 0 5]`
+	)
+	qry := NewQuery().WithIndex("test").WithQueryString("shoes").
+		AddFilter(NewQueryFilter("price").WithMaxExclusive(100))
+
+	agg, err := facetAggregate(qry, "colour", "", 5)
+	require.NoError(t, err)
+	require.Equal(t, expected, agg.String())
+
+	qry = NewQuery().WithIndex("test").WithQueryString("@brand:{$brand}").AddParam("brand", "acme").
+		WithDialect(2).WithTimeout(time.Second).AddGeoFilter("loc", -0.1, 51.5, 10, Kilometers)
+	qry.Verbatim = true
+	agg, err = facetAggregate(qry, "@colour", "|", 0)
+	require.NoError(t, err)
+	require.Equal(t, `[FT.AGGREGATE test (@brand:{$brand}) (@loc:[-0.1 51.5 10 km]) VERBATIM LOAD 1 @colour TIMEOUT 1000 `+
+		`APPLY split(@colour, "|") AS value GROUPBY 1 @value REDUCE COUNT 0 AS count SORTBY 2 @count DESC PARAMS 2 brand acme DIALECT 2]`, agg.String())
+
+	qry = NewQuery().WithIndex("test")
+	qry.Language = "german"
+	_, err = facetAggregate(qry, "colour", "", 0)
+	require.EqualError(t, err, "ftsearch: tag facets do not support LANGUAGE")
+	_, err = facetAggregate(NewQuery().WithIndex("test").AddKey("doc:1"), "colour", "", 0)
+	require.EqualError(t, err, "ftsearch: tag facets do not support INKEYS")
+	_, err = facetAggregate(NewQuery().WithIndex("test"), "colour", "||", 0)
+	require.Error(t, err)
+}
+
+func TestFilteredQueryString(t *testing.T) {
+	require.Equal(t, "*", NewQuery().filteredQueryString())
+	require.Equal(t, "hello", NewQuery().WithQueryString("hello").filteredQueryString())
+	require.Equal(t, "@n:[1 +inf]",
+		NewQuery().AddFilter(NewQueryFilter("n").WithMinInclusive(1)).filteredQueryString())
+}